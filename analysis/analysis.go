@@ -0,0 +1,265 @@
+// Package analysis runs a UCI engine over all the positions of a finished game, finds inaccuracies,
+// mistakes and blunders, and produces an annotated game.
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+type Options struct {
+	// Search limits for each position, like depth, nodes or movetime.
+	GoOptions uci.GoOptions
+
+	// Thresholds for the drop of win probability, from 0 to 1, to classify the move as inaccuracy,
+	// mistake or blunder.
+	//
+	// Zero means default.
+	Inaccuracy float64
+	Mistake    float64
+	Blunder    float64
+
+	// Maximum number of moves in the best line attached to bad moves. Zero means that the lines are
+	// not attached.
+	//
+	// None means default.
+	VariationLen maybe.Maybe[int]
+
+	// Receives the search updates.
+	Consumer uci.InfoConsumer
+
+	// Called after each analyzed position.
+	OnProgress func(done, total int)
+}
+
+func (o Options) Clone() Options {
+	o.GoOptions = o.GoOptions.Clone()
+	return o
+}
+
+func (o *Options) FillDefaults() {
+	if o.Inaccuracy == 0 {
+		o.Inaccuracy = 0.05
+	}
+	if o.Mistake == 0 {
+		o.Mistake = 0.1
+	}
+	if o.Blunder == 0 {
+		o.Blunder = 0.15
+	}
+	if o.VariationLen.IsNone() {
+		o.VariationLen = maybe.Some(8)
+	}
+}
+
+func (o *Options) Validate() error {
+	g := &o.GoOptions
+	if g.Depth.IsNone() && g.Nodes.IsNone() && g.Movetime.IsNone() {
+		return fmt.Errorf("no search limit")
+	}
+	if g.Infinite || g.Ponder {
+		return fmt.Errorf("search must be finite")
+	}
+	if !(0 < o.Inaccuracy && o.Inaccuracy <= o.Mistake && o.Mistake <= o.Blunder && o.Blunder <= 1) {
+		return fmt.Errorf("bad thresholds")
+	}
+	if o.VariationLen.GetOr(0) < 0 {
+		return fmt.Errorf("negative variation length")
+	}
+	return nil
+}
+
+type MoveInfo struct {
+	Move chess.Move
+	Side chess.Color
+
+	// Score before the move and the best line found by the engine, from the mover's point of
+	// view.
+	Best   uci.Score
+	BestPV []chess.Move
+
+	// Score after the move, from the mover's point of view. None if the game is over after the
+	// move by checkmate or stalemate.
+	Played maybe.Maybe[uci.Score]
+
+	// Expected result for the mover before and after the move, from 0 to 1.
+	WinBefore float64
+	WinAfter  float64
+
+	Class Class
+
+	// Accuracy of the move, from 0 to 100.
+	Accuracy float64
+}
+
+// WinDrop returns how much the expected result decreased due to the move.
+func (m *MoveInfo) WinDrop() float64 {
+	return max(0, m.WinBefore-m.WinAfter)
+}
+
+// IsBest returns true if the move is the one suggested by the engine.
+func (m *MoveInfo) IsBest() bool {
+	return len(m.BestPV) != 0 && m.BestPV[0] == m.Move
+}
+
+type Report struct {
+	game *chess.Game
+	o    Options
+
+	Moves []MoveInfo
+
+	// Average accuracy of the moves for each side. Zero if the side made no moves.
+	Accuracy [chess.ColorMax]float64
+
+	// Number of moves in each class for each side.
+	Counts [chess.ColorMax][ClassMax]int
+}
+
+// Game returns the analyzed game. The caller must not modify it.
+func (r *Report) Game() *chess.Game { return r.game }
+
+type posEval struct {
+	score maybe.Maybe[uci.Score] // None for terminal positions.
+	pv    []chess.Move
+	win   float64 // From the side to move's point of view.
+}
+
+func evalPos(ctx context.Context, e *uci.Engine, g *chess.Game, o *Options) (posEval, error) {
+	b := g.CurBoard()
+	if !b.HasLegalMoves() {
+		if b.IsCheck() {
+			return posEval{win: 0}, nil
+		}
+		return posEval{win: 0.5}, nil
+	}
+
+	if err := e.SetPosition(ctx, g); err != nil {
+		return posEval{}, fmt.Errorf("set position: %w", err)
+	}
+	search, err := e.Go(ctx, o.GoOptions, o.Consumer)
+	if err != nil {
+		return posEval{}, fmt.Errorf("go: %w", err)
+	}
+	if err := search.Wait(ctx); err != nil {
+		return posEval{}, fmt.Errorf("wait: %w", err)
+	}
+	status := search.Status()
+	score, ok := status.Score.TryGet()
+	if !ok {
+		return posEval{}, fmt.Errorf("no score")
+	}
+
+	// The engine may return an illegal principal variation, so we just cut it at the first illegal
+	// move.
+	var pv []chess.Move
+	board := b.Clone()
+	for _, um := range status.PV {
+		mv, err := chess.LegalMoveFromUCIMove(um, board)
+		if err != nil {
+			break
+		}
+		pv = append(pv, mv)
+		_ = board.MakeLegalMove(mv)
+	}
+	if len(pv) == 0 {
+		best, err := search.BestMove()
+		if err != nil {
+			return posEval{}, fmt.Errorf("best move: %w", err)
+		}
+		pv = []chess.Move{best}
+	}
+
+	return posEval{
+		score: maybe.Some(score),
+		pv:    pv,
+		win:   WinProbability(score),
+	}, nil
+}
+
+func (o *Options) classify(m *MoveInfo) Class {
+	if m.IsBest() {
+		return ClassGood
+	}
+	switch drop := m.WinDrop(); {
+	case drop >= o.Blunder:
+		return ClassBlunder
+	case drop >= o.Mistake:
+		return ClassMistake
+	case drop >= o.Inaccuracy:
+		return ClassInaccuracy
+	default:
+		return ClassGood
+	}
+}
+
+// Analyze runs the engine over every position of the game and classifies the moves.
+func Analyze(ctx context.Context, e *uci.Engine, g *chess.Game, o Options) (*Report, error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+
+	start, err := chess.NewBoard(g.StartPos())
+	if err != nil {
+		return nil, fmt.Errorf("bad start position: %w", err)
+	}
+	if err := e.UCINewGame(ctx, true); err != nil {
+		return nil, fmt.Errorf("ucinewgame: %w", err)
+	}
+
+	n := g.Len()
+	evals := make([]posEval, n+1)
+	cur := chess.NewGameWithPosition(start)
+	for i := range n + 1 {
+		if i != 0 {
+			cur.PushLegalMove(g.MoveAt(i - 1))
+		}
+		evals[i], err = evalPos(ctx, e, cur, &o)
+		if err != nil {
+			return nil, fmt.Errorf("position #%v: %w", i, err)
+		}
+		if o.OnProgress != nil {
+			o.OnProgress(i+1, n+1)
+		}
+	}
+
+	r := &Report{
+		game:  g.Clone(),
+		o:     o,
+		Moves: make([]MoveInfo, n),
+	}
+	var accSum [chess.ColorMax]float64
+	side := start.Side()
+	for i := range n {
+		m := &r.Moves[i]
+		m.Move = g.MoveAt(i)
+		m.Side = side
+		m.Best = evals[i].score.Get()
+		m.BestPV = evals[i].pv
+		if s, ok := evals[i+1].score.TryGet(); ok {
+			m.Played = maybe.Some(negScore(s))
+		}
+		m.WinBefore = evals[i].win
+		m.WinAfter = 1 - evals[i+1].win
+		m.Class = o.classify(m)
+		m.Accuracy = MoveAccuracy(m.WinBefore, m.WinAfter)
+		r.Counts[side][m.Class]++
+		accSum[side] += m.Accuracy
+		side = side.Inv()
+	}
+	for c := range chess.ColorMax {
+		total := 0
+		for _, cnt := range r.Counts[c] {
+			total += cnt
+		}
+		if total != 0 {
+			r.Accuracy[c] = accSum[c] / float64(total)
+		}
+	}
+	return r, nil
+}
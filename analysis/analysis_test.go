@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWinProbability(t *testing.T) {
+	assert.Equal(t, 0.5, WinProbability(uci.ScoreCentipawns(0)))
+	assert.InDelta(t, 0.59, WinProbability(uci.ScoreCentipawns(100)), 0.01)
+	assert.InDelta(t, 0.41, WinProbability(uci.ScoreCentipawns(-100)), 0.01)
+	assert.Equal(t, 1.0, WinProbability(uci.ScoreMate(3)))
+	assert.Equal(t, 0.0, WinProbability(uci.ScoreMate(-3)))
+
+	assert.InDelta(t, 100.0, MoveAccuracy(0.5, 0.5), 1e-3)
+	assert.InDelta(t, 100.0, MoveAccuracy(0.5, 0.7), 1e-3)
+	assert.InDelta(t, 8.5, MoveAccuracy(0.5, 0), 0.1)
+}
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+
+	// The fake engine sees the mate after Black's blunder, and prefers g6 for Black.
+	blunder, err := chess.RawBoardFromFEN(
+		"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4",
+	)
+	require.NoError(t, err)
+	fake := ucitest.NewEngine(ucitest.EngineOptions{
+		Choose: func(b *chess.Board, candidates []chess.Move) chess.Move {
+			for _, mv := range candidates {
+				if mv.UCI() == "g7g6" {
+					return mv
+				}
+			}
+			return candidates[0]
+		},
+		Eval: func(b *chess.Board) uci.Score {
+			if b.Raw() == blunder {
+				return uci.ScoreMate(1)
+			}
+			return uci.ScoreCentipawns(0)
+		},
+	})
+	e := uci.NewEngine(ctx, fake, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+
+	g, err := chess.GameFromUCIList(chess.InitialBoard(), "e2e4 e7e5 f1c4 b8c6 d1h5 g8f6 h5f7")
+	require.NoError(t, err)
+	g.SetOutcome(g.CalcOutcome())
+
+	progress := 0
+	r, err := Analyze(ctx, e, g, Options{
+		GoOptions:  uci.GoOptions{Depth: maybe.Some[int64](1)},
+		OnProgress: func(done, total int) { progress = done },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8, progress)
+	require.Len(t, r.Moves, 7)
+
+	for i, m := range r.Moves {
+		if i == 5 {
+			continue
+		}
+		assert.Equal(t, ClassGood, m.Class, "move %v", i)
+	}
+	m := r.Moves[5]
+	assert.Equal(t, chess.ColorBlack, m.Side)
+	assert.Equal(t, ClassBlunder, m.Class)
+	assert.Equal(t, maybe.Some(uci.ScoreMate(-1)), m.Played)
+	assert.Equal(t, "g7g6", m.BestPV[0].UCI())
+	assert.Equal(t, 0.5, m.WinDrop())
+	assert.True(t, r.Moves[6].Played.IsNone())
+
+	assert.InDelta(t, 100.0, r.Accuracy[chess.ColorWhite], 1e-3)
+	assert.InDelta(t, 69.5, r.Accuracy[chess.ColorBlack], 0.1)
+	assert.Equal(t, [ClassMax]int{2, 0, 0, 1}, r.Counts[chess.ColorBlack])
+
+	s, err := r.Styled(chess.GameStyle{
+		Move:       chess.MoveStyleSAN,
+		MoveNumber: chess.MoveNumberStyle{Enabled: true},
+		Outcome:    chess.GameOutcomeShow,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(s, "1. e4 {[%eval 0.00]} 1... e5 {[%eval 0.00]} 2. Bc4"))
+	assert.Contains(t, s, "3... Nf6 $4 {[%eval #1]} {Blunder. g6 was best.} (3... g6) 4. Qxf7# 1-0")
+
+	r.o.VariationLen = maybe.Some(0)
+	s, err = r.Styled(chess.GameStyle{Move: chess.MoveStyleSAN})
+	require.NoError(t, err)
+	assert.Contains(t, s, "{Blunder. g6 was best.} Qxf7#")
+}
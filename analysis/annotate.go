@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+func whiteScoreString(m *MoveInfo) (string, bool) {
+	s, ok := m.Played.TryGet()
+	if !ok {
+		return "", false
+	}
+	if m.Side == chess.ColorBlack {
+		s = negScore(s)
+	}
+	return evalString(s), true
+}
+
+// Annotations converts the report into the annotations for Game.StyledExt. Each move gets the
+// engine evaluation in "[%eval ...]" format. Inaccuracies, mistakes and blunders also get the NAG,
+// the comment with the best move and the best line as a variation.
+func (r *Report) Annotations() (chess.GameAnnotations, error) {
+	n := len(r.Moves)
+	ga := chess.GameAnnotations{
+		Comments:   make([][]string, n+1),
+		NAGs:       make([][]chess.NAG, n),
+		Variations: make([][][]chess.Move, n),
+	}
+	w := r.game.Walk()
+	w.First()
+	for i := range r.Moves {
+		m := &r.Moves[i]
+		if s, ok := whiteScoreString(m); ok {
+			ga.Comments[i+1] = append(ga.Comments[i+1], fmt.Sprintf("[%%eval %v]", s))
+		}
+		if m.Class != ClassGood {
+			best, err := m.BestPV[0].Styled(w.Board(), chess.MoveStyleSAN)
+			if err != nil {
+				return chess.GameAnnotations{}, fmt.Errorf("style best move #%v: %w", i+1, err)
+			}
+			ga.NAGs[i] = []chess.NAG{m.Class.NAG()}
+			ga.Comments[i+1] = append(ga.Comments[i+1], fmt.Sprintf("%v. %v was best.", m.Class, best))
+			if n := r.o.VariationLen.Get(); n != 0 {
+				ga.Variations[i] = [][]chess.Move{m.BestPV[:min(len(m.BestPV), n)]}
+			}
+		}
+		_ = w.Next()
+	}
+	return ga, nil
+}
+
+// Styled returns the annotated game. With MoveStyleSAN, the result is a valid PGN movetext.
+func (r *Report) Styled(style chess.GameStyle) (string, error) {
+	ga, err := r.Annotations()
+	if err != nil {
+		return "", fmt.Errorf("annotations: %w", err)
+	}
+	return r.game.StyledExt(style, ga)
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+)
+
+type Class uint8
+
+const (
+	ClassGood Class = iota
+	ClassInaccuracy
+	ClassMistake
+	ClassBlunder
+	ClassMax
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassGood:
+		return "Good"
+	case ClassInaccuracy:
+		return "Inaccuracy"
+	case ClassMistake:
+		return "Mistake"
+	case ClassBlunder:
+		return "Blunder"
+	default:
+		return fmt.Sprintf("Class(%d)", uint8(c))
+	}
+}
+
+func (c Class) NAG() chess.NAG {
+	switch c {
+	case ClassInaccuracy:
+		return chess.NAGDubiousMove
+	case ClassMistake:
+		return chess.NAGMistake
+	case ClassBlunder:
+		return chess.NAGBlunder
+	default:
+		return chess.NAGNone
+	}
+}
+
+// WinProbability converts the score into the expected result for the side which has this score,
+// from 0 (certain loss) to 1 (certain win). The same logistic model as on Lichess is used.
+func WinProbability(s uci.Score) float64 {
+	if s.IsMate() {
+		if s.IsWinMate() {
+			return 1
+		}
+		return 0
+	}
+	cp, _ := s.Centipawns()
+	return 1 / (1 + math.Exp(-0.00368208*float64(cp)))
+}
+
+// MoveAccuracy converts the drop of win probability into the accuracy of the move, from 0 to 100.
+func MoveAccuracy(winBefore, winAfter float64) float64 {
+	drop := max(0, winBefore-winAfter) * 100
+	acc := 103.1668*math.Exp(-0.04354*drop) - 3.1669
+	return min(max(acc, 0), 100)
+}
+
+// Returns the score from the opposite side's point of view. Must not be called on "mate 0".
+func negScore(s uci.Score) uci.Score {
+	if m, ok := s.Mate(); ok {
+		return uci.ScoreMate(-m)
+	}
+	cp, _ := s.Centipawns()
+	return uci.ScoreCentipawns(-cp)
+}
+
+// Formats the score from White's point of view in the format of "%eval" PGN command.
+func evalString(s uci.Score) string {
+	if m, ok := s.Mate(); ok {
+		return fmt.Sprintf("#%v", m)
+	}
+	cp, _ := s.Centipawns()
+	return fmt.Sprintf("%.2f", float64(cp)/100)
+}
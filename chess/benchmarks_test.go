@@ -178,3 +178,37 @@ func BenchmarkHasLegalMoves(b *testing.B) {
 		})
 	}
 }
+
+// CalcOutcome is called after each move in clock.Game, so the dead position detection must be
+// cheap for the locked positions.
+func BenchmarkCalcOutcomeLocked(b *testing.B) {
+	for _, v := range []struct {
+		name string
+		fen  string
+		dead bool
+	}{
+		{name: "dead", fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/8 w - - 0 1", dead: true},
+		{name: "deadBishops", fen: "2b5/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/8 w - - 0 1", dead: true},
+		{name: "notDead", fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/2B1K3/8 w - - 0 1", dead: false},
+		{name: "rook", fen: "r7/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/R7 w - - 0 1", dead: false},
+		{
+			name: "chain",
+			fen:  "r1bqk2r/pp3ppp/2n1p3/2ppP3/3P4/2P2N2/PP3PPP/R1BQKB1R w KQkq - 0 1",
+			dead: false,
+		},
+	} {
+		b.Run(v.name, func(b *testing.B) {
+			board, err := chess.BoardFromFEN(v.fen)
+			if err != nil {
+				b.Fatalf("cannot parse board: %v", err)
+			}
+			if dead := board.CalcOutcome().Verdict() == chess.VerdictDeadPosition; dead != v.dead {
+				b.Fatalf("bad verdict: dead = %v, want %v", dead, v.dead)
+			}
+			b.ResetTimer()
+			for range b.N {
+				board.CalcOutcome()
+			}
+		})
+	}
+}
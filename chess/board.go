@@ -644,6 +644,34 @@ func (b *Board) IsInsufficientMaterial() bool {
 	return allWithoutKings == bishops
 }
 
+// HasMatingMaterial reports whether c can checkmate the opponent by any legal sequence of moves,
+// even with the opponent's help. The check is conservative: false is returned only for lone king,
+// king and a minor piece against lone king, and kings with bishops on the cells of the same color.
+func (b *Board) HasMatingMaterial(c Color) bool {
+	kings := b.BbPiece(ColorWhite, PieceKing) | b.BbPiece(ColorBlack, PieceKing)
+	our := b.BbColor(c) &^ kings
+	their := b.BbColor(c.Inv()) &^ kings
+	if our.IsEmpty() {
+		return false
+	}
+
+	// King and a minor piece against lone king
+	minors := b.BbPiece(c, PieceKnight) | b.BbPiece(c, PieceBishop)
+	if their.IsEmpty() && our == minors && our.Len() == 1 {
+		return false
+	}
+
+	// Kings and bishops of the same cell color. The opponent's bishops can never block the flight
+	// cells of the other color, so even the helpmate is not possible.
+	all := our | their
+	bishops := b.BbPiece(ColorWhite, PieceBishop) | b.BbPiece(ColorBlack, PieceBishop)
+	if all == bishops && ((all & BbLight).IsEmpty() || (all & BbDark).IsEmpty()) {
+		return false
+	}
+
+	return true
+}
+
 func (b *Board) CalcOutcome() Outcome {
 	// First, we verify for checkmate or stalemate, as force outcome take precedence over
 	// non-force ones.
@@ -659,6 +687,9 @@ func (b *Board) CalcOutcome() Outcome {
 	if b.IsInsufficientMaterial() {
 		return MustDrawOutcome(VerdictInsufficientMaterial)
 	}
+	if b.isLocked() {
+		return MustDrawOutcome(VerdictDeadPosition)
+	}
 
 	// Check for 50/75 move rule. Note that check for 50 move rule must
 	// come after all other ones, because it is non-strict.
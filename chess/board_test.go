@@ -286,3 +286,57 @@ func TestBoardOutcome(t *testing.T) {
 		assert.Equal(t, v.out, b.CalcOutcome())
 	}
 }
+
+func TestBoardHasMatingMaterial(t *testing.T) {
+	for _, v := range []struct {
+		fen   string
+		white bool
+		black bool
+	}{
+		{fen: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", white: true, black: true},
+		{fen: "8/3k4/8/8/8/8/1K6/8 w - - 0 1", white: false, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/7Q w - - 0 1", white: true, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/7N w - - 0 1", white: false, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/7B w - - 0 1", white: false, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/6NN w - - 0 1", white: true, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/4r2N w - - 0 1", white: true, black: true},
+		{fen: "8/3k4/8/8/8/4p3/1K6/7N w - - 0 1", white: true, black: true},
+		{fen: "8/3k4/8/8/8/8/1K6/5b1B w - - 0 1", white: false, black: false},
+		{fen: "8/3k4/8/8/8/8/1K6/4b2B w - - 0 1", white: true, black: true},
+	} {
+		b, err := BoardFromFEN(v.fen)
+		require.NoError(t, err)
+		assert.Equal(t, v.white, b.HasMatingMaterial(ColorWhite), v.fen)
+		assert.Equal(t, v.black, b.HasMatingMaterial(ColorBlack), v.fen)
+	}
+}
+
+func TestBoardDeadPosition(t *testing.T) {
+	for _, v := range []struct {
+		fen  string
+		dead bool
+	}{
+		{fen: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", dead: false},
+		{fen: "8/3k4/8/8/8/8/1K6/8 w - - 0 1", dead: true},
+		{fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/4K3/8 w - - 0 1", dead: true},
+		{fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/8 w - - 0 1", dead: true},
+		{fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/2B1K3/8 w - - 0 1", dead: false},
+		{fen: "8/8/4k3/1p1p1p2/1P1P1P2/8/4K3/8 w - - 0 1", dead: false},
+		{fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/4K3/R7 w - - 0 1", dead: false},
+		{fen: "2b5/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/8 w - - 0 1", dead: true},
+		{fen: "8/2b5/4k3/1p1p1p1p/1P1P1P1P/8/2B1K3/8 w - - 0 1", dead: false},
+		{fen: "8/8/4k3/1p1p1p1p/1P1P1P1P/8/3BK3/8 b - - 0 1", dead: true},
+		{fen: "8/8/4k3/p1p1p1p1/1P1P1P1P/8/4K3/8 w - - 0 1", dead: false},
+		{fen: "8/8/4k3/1p1p1p1p/pP1P1P1P/P7/4K3/8 w - - 0 1", dead: true},
+		{fen: "7k/8/8/1p1p1p1p/1P1P1P1P/8/8/K7 w - - 0 1", dead: true},
+	} {
+		b, err := BoardFromFEN(v.fen)
+		require.NoError(t, err)
+		assert.Equal(t, v.dead, b.IsDeadPosition(), v.fen)
+	}
+
+	b, err := BoardFromFEN("8/8/4k3/1p1p1p1p/1P1P1P1P/8/4K3/8 w - - 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, MustDrawOutcome(VerdictDeadPosition), b.CalcOutcome())
+	assert.True(t, b.CalcOutcome().Passes(VerdictFilterStrict))
+}
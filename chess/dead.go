@@ -0,0 +1,104 @@
+package chess
+
+func bbKingSteps(b Bitboard) Bitboard {
+	notA, notH := b&^BbFile(FileA), b&^BbFile(FileH)
+	return b<<8 | b>>8 | notH<<1 | notA>>1 | bbBishopSteps(b)
+}
+
+func bbBishopSteps(b Bitboard) Bitboard {
+	notA, notH := b&^BbFile(FileA), b&^BbFile(FileH)
+	return notH<<9 | notH>>7 | notA<<7 | notA>>9
+}
+
+// Returns all the cells reachable from start by repeated steps over the passable cells.
+func bbFlood(start, passable Bitboard, step func(Bitboard) Bitboard) Bitboard {
+	res := start
+	for {
+		next := res | (step(res) & passable)
+		if next == res {
+			return res
+		}
+		res = next
+	}
+}
+
+func bbPawnAttacks(c Color, b Bitboard) Bitboard {
+	return pawnAdvanceLeft(c, b) | pawnAdvanceRight(c, b)
+}
+
+// Cheap check which rules out most of the positions. The position may be locked only if all the
+// pawns are blocked by other pawns, and only kings and bishops can move apart from them.
+func (b *Board) mayBeLocked() bool {
+	wp, bp := b.BbPiece(ColorWhite, PiecePawn), b.BbPiece(ColorBlack, PiecePawn)
+	pawns := wp | bp
+	if pawns.IsEmpty() {
+		return false
+	}
+	if pawnAdvanceForward(ColorWhite, wp)&^pawns != 0 ||
+		pawnAdvanceForward(ColorBlack, bp)&^pawns != 0 {
+		return false
+	}
+	for c := range ColorMax {
+		others := b.BbColor(c) &^ (b.BbPiece(c, PiecePawn) | b.BbPiece(c, PieceKing) |
+			b.BbPiece(c, PieceBishop))
+		if !others.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Proves that no capture and no check is ever possible, so the pawns stay in place forever and
+// nobody can be checkmated.
+//
+// While the pawns stay in place, the king can only visit the cells connected to its own cell which
+// are free of pawns and not attacked by the enemy pawns, and the bishop can only visit the cells of
+// its color connected to its own cell by the diagonals free of pawns. These areas are computed
+// assuming that kings and bishops never block each other, so they include all the cells which may
+// be ever visited. Then, no capture or check is possible if no area touches the enemy pieces or
+// their areas.
+func (b *Board) isLocked() bool {
+	if !b.mayBeLocked() {
+		return false
+	}
+	pawns := b.BbPiece(ColorWhite, PiecePawn) | b.BbPiece(ColorBlack, PiecePawn)
+	var pawnAttacks, kingArea, bishopArea [ColorMax]Bitboard
+	for c := range ColorMax {
+		pawnAttacks[c] = bbPawnAttacks(c, b.BbPiece(c, PiecePawn))
+	}
+	for c := range ColorMax {
+		inv := c.Inv()
+		their := b.BbPiece(inv, PiecePawn)
+		if !(pawnAttacks[c] & their).IsEmpty() {
+			return false
+		}
+		// Let the king step onto the enemy pawns not defended by other pawns, to detect captures.
+		passable := ^(pawnAttacks[inv] | b.BbPiece(c, PiecePawn))
+		kingArea[c] = bbFlood(b.BbPiece(c, PieceKing), passable, bbKingSteps)
+		if !(kingArea[c] & (pawnAttacks[inv] | their)).IsEmpty() {
+			return false
+		}
+		bishopArea[c] = bbFlood(b.BbPiece(c, PieceBishop), ^pawns, bbBishopSteps)
+		if !(bishopArea[c] & pawnAttacks[inv]).IsEmpty() {
+			return false
+		}
+	}
+	for c := range ColorMax {
+		inv := c.Inv()
+		attacks := bishopArea[c] | bbBishopSteps(bishopArea[c])
+		if !(attacks & (b.BbPiece(inv, PiecePawn) | kingArea[inv] | bishopArea[inv])).IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDeadPosition reports whether no checkmate can be reached by any sequence of legal moves. It
+// is a superset of IsInsufficientMaterial.
+//
+// Apart from insufficient material, only locked positions are recognized, i.e. the ones in which
+// all the pawns are blocked, only kings and bishops are left, and it can be proven that no capture
+// and no check is ever possible. Other dead positions are not detected.
+func (b *Board) IsDeadPosition() bool {
+	return b.IsInsufficientMaterial() || b.isLocked()
+}
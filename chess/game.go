@@ -73,8 +73,33 @@ type GameStyle struct {
 	Outcome    GameOutcomeStyle
 }
 
+// NAG is a Numeric Annotation Glyph, as defined in the PGN standard.
+type NAG uint8
+
+const (
+	NAGNone            NAG = 0
+	NAGGoodMove        NAG = 1
+	NAGMistake         NAG = 2
+	NAGBrilliantMove   NAG = 3
+	NAGBlunder         NAG = 4
+	NAGInterestingMove NAG = 5
+	NAGDubiousMove     NAG = 6
+)
+
+func (n NAG) String() string {
+	return fmt.Sprintf("$%d", uint8(n))
+}
+
 type GameAnnotations struct {
+	// Comments[0] is placed before the first move, and Comments[i] is placed after the i-th move.
 	Comments [][]string
+
+	// NAGs[i] are attached to the i-th move, counting from zero.
+	NAGs [][]NAG
+
+	// Variations[i] are the alternatives to the i-th move, counting from zero. Each variation is a
+	// sequence of moves starting from the position before the i-th move.
+	Variations [][][]Move
 }
 
 type Game struct {
@@ -309,6 +334,36 @@ func doAddComments(b *strings.Builder, cs []string) {
 	}
 }
 
+func doAddVariation(b *strings.Builder, board *Board, moveNumber int, mvs []Move, style GameStyle) error {
+	board = board.Clone()
+	_ = b.WriteByte('(')
+	for i, mv := range mvs {
+		if i != 0 {
+			_ = b.WriteByte(' ')
+		}
+		if style.MoveNumber.Enabled {
+			if board.Side() == ColorWhite {
+				_, _ = fmt.Fprintf(b, "%v. ", moveNumber)
+			} else if i == 0 {
+				_, _ = fmt.Fprintf(b, "%v... ", moveNumber)
+			}
+		}
+		s, err := mv.Styled(board, style.Move)
+		if err != nil {
+			return fmt.Errorf("style move #%d: %w", i+1, err)
+		}
+		_, _ = b.WriteString(s)
+		if _, err := board.MakeMove(mv); err != nil {
+			return fmt.Errorf("make move #%d: %w", i+1, err)
+		}
+		if board.Side() == ColorWhite {
+			moveNumber++
+		}
+	}
+	_ = b.WriteByte(')')
+	return nil
+}
+
 func (g *Game) StyledExt(style GameStyle, ga GameAnnotations) (string, error) {
 	var b strings.Builder
 
@@ -349,11 +404,28 @@ func (g *Game) StyledExt(style GameStyle, ga GameAnnotations) (string, error) {
 				return "", fmt.Errorf("style move #%d: %w", i+1, err)
 			}
 			_, _ = b.WriteString(s)
+			if len(ga.NAGs) > i {
+				for _, n := range ga.NAGs[i] {
+					_, _ = fmt.Fprintf(&b, " %v", n)
+				}
+			}
 			if len(ga.Comments) > i+1 && len(ga.Comments[i+1]) != 0 {
 				_ = b.WriteByte(' ')
 				doAddComments(&b, ga.Comments[i+1])
 				mustNumber = true
 			}
+			if len(ga.Variations) > i {
+				for j, v := range ga.Variations[i] {
+					if len(v) == 0 {
+						continue
+					}
+					_ = b.WriteByte(' ')
+					if err := doAddVariation(&b, w.Board(), moveNumber, v, style); err != nil {
+						return "", fmt.Errorf("variation #%d for move #%d: %w", j+1, i+1, err)
+					}
+					mustNumber = true
+				}
+			}
 			if w.Board().Side() == ColorBlack {
 				moveNumber++
 			}
@@ -495,6 +495,48 @@ func TestGamePushExtra(t *testing.T) {
 	assert.Equal(t, 6, g.Len())
 }
 
+func TestGameStyledExtVariations(t *testing.T) {
+	g, err := GameFromUCIList(InitialBoard(), "e2e4 e7e5 g1f3")
+	require.NoError(t, err)
+	g.SetOutcome(RunningOutcome())
+
+	line := func(b *Board, ucis string) []Move {
+		g, err := GameFromUCIList(b, ucis)
+		require.NoError(t, err)
+		var res []Move
+		for i := range g.Len() {
+			res = append(res, g.MoveAt(i))
+		}
+		return res
+	}
+	afterE4, err := BoardFromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	require.NoError(t, err)
+
+	s, err := g.StyledExt(GameStyle{
+		Move:       MoveStyleSAN,
+		MoveNumber: MoveNumberStyle{Enabled: true},
+		Outcome:    GameOutcomeShow,
+	}, GameAnnotations{
+		Comments: [][]string{nil, nil, {"main line"}},
+		NAGs:     [][]NAG{{NAGGoodMove}, {NAGDubiousMove, NAGInterestingMove}},
+		Variations: [][][]Move{
+			{line(InitialBoard(), "d2d4 d7d5"), nil},
+			{line(afterE4, "c7c5 g1f3"), line(afterE4, "e7e6")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"1. e4 $1 (1. d4 d5) 1... e5 $6 $5 {main line} (1... c5 2. Nf3) (1... e6) 2. Nf3 *",
+		s,
+	)
+
+	_, err = g.StyledExt(GameStyle{Move: MoveStyleSAN}, GameAnnotations{
+		Variations: [][][]Move{{line(afterE4, "c7c5")}},
+	})
+	assert.Error(t, err)
+}
+
 func TestGameStyledExt(t *testing.T) {
 	for _, v := range []struct {
 		src   string
@@ -574,6 +574,9 @@ const (
 	VerdictMoves50              Verdict = 37
 	VerdictRepeat3              Verdict = 38
 	VerdictDrawAgreement        Verdict = 39
+	VerdictDrawAdjudication     Verdict = 40
+	VerdictTimeoutVsNoMaterial  Verdict = 41
+	VerdictDeadPosition         Verdict = 42
 
 	// Win game verdicts
 	VerdictWinUnknown      Verdict = 64
@@ -583,6 +586,10 @@ const (
 	VerdictEngineError     Verdict = 68
 	VerdictResign          Verdict = 69
 	VerdictOpponentAbandon Verdict = 70
+	VerdictWinAdjudication Verdict = 71
+
+	// Aborted game verdicts. The game is finished, but has no result.
+	VerdictAbort Verdict = 96
 )
 
 type VerdictKind uint8
@@ -591,6 +598,7 @@ const (
 	VerdictKindRunning VerdictKind = 0
 	VerdictKindDraw    VerdictKind = 1
 	VerdictKindWin     VerdictKind = 2
+	VerdictKindAborted VerdictKind = 3
 )
 
 type VerdictFilter uint8
@@ -601,6 +609,18 @@ const (
 	VerdictFilterRelaxed
 )
 
+// IsValid reports whether v is one of the known verdicts.
+func (v Verdict) IsValid() bool {
+	return v == VerdictRunning ||
+		(VerdictDrawUnknown <= v && v <= VerdictDeadPosition) ||
+		(VerdictWinUnknown <= v && v <= VerdictWinAdjudication) ||
+		v == VerdictAbort
+}
+
+func (f VerdictFilter) IsValid() bool {
+	return f <= VerdictFilterRelaxed
+}
+
 func (v Verdict) Kind() VerdictKind {
 	return VerdictKind(uint8(v) >> 5)
 }
@@ -613,7 +633,7 @@ func (v Verdict) Passes(filter VerdictFilter) bool {
 	switch v {
 	case VerdictCheckmate, VerdictStalemate, VerdictRunning:
 		return filter >= VerdictFilterForce
-	case VerdictInsufficientMaterial, VerdictMoves75, VerdictRepeat5:
+	case VerdictInsufficientMaterial, VerdictDeadPosition, VerdictMoves75, VerdictRepeat5:
 		return filter >= VerdictFilterStrict
 	case VerdictMoves50, VerdictRepeat3:
 		return filter >= VerdictFilterRelaxed
@@ -642,6 +662,12 @@ func (v Verdict) String() string {
 		return "threefold repetition"
 	case VerdictDrawAgreement:
 		return "draw by agreement"
+	case VerdictDrawAdjudication:
+		return "draw by adjudication"
+	case VerdictTimeoutVsNoMaterial:
+		return "draw by timeout vs insufficient material"
+	case VerdictDeadPosition:
+		return "dead position"
 	case VerdictWinUnknown:
 		return "win by unknown reason"
 	case VerdictCheckmate:
@@ -656,6 +682,10 @@ func (v Verdict) String() string {
 		return "opponent resigns"
 	case VerdictOpponentAbandon:
 		return "opponent abandons the game"
+	case VerdictWinAdjudication:
+		return "win by adjudication"
+	case VerdictAbort:
+		return "game aborted"
 	default:
 		return "invalid"
 	}
@@ -735,6 +765,11 @@ func RunningOutcome() Outcome {
 	return Outcome{verdict: VerdictRunning}
 }
 
+// AbortedOutcome returns the outcome of the game which is finished without result.
+func AbortedOutcome() Outcome {
+	return Outcome{verdict: VerdictAbort}
+}
+
 func DrawOutcome(verdict Verdict) (Outcome, bool) {
 	if verdict.Kind() == VerdictKindDraw {
 		return Outcome{verdict: verdict}, true
@@ -793,7 +828,7 @@ func (o Outcome) Passes(filter VerdictFilter) bool {
 
 func (o Outcome) Status() Status {
 	switch o.verdict.Kind() {
-	case VerdictKindRunning:
+	case VerdictKindRunning, VerdictKindAborted:
 		return StatusRunning
 	case VerdictKindDraw:
 		return StatusDraw
@@ -824,6 +859,8 @@ func (o Outcome) String() string {
 		return fmt.Sprintf("%s resigns", s.Inv().LongString())
 	case VerdictOpponentAbandon:
 		return fmt.Sprintf("%s abandons the game", s.Inv().LongString())
+	case VerdictWinAdjudication:
+		return fmt.Sprintf("%s wins by adjudication", s.LongString())
 	default:
 		return "invalid"
 	}
@@ -154,4 +154,13 @@ func TestOutcome(t *testing.T) {
 
 	_, ok = o5.Status().Winner()
 	assert.False(t, ok)
+
+	o6 := AbortedOutcome()
+	assert.True(t, o6.IsFinished())
+	assert.Equal(t, StatusRunning, o6.Status())
+	assert.Equal(t, "game aborted", o6.String())
+	_, ok = o6.Side()
+	assert.False(t, ok)
+	_, ok = DrawOutcome(VerdictAbort)
+	assert.False(t, ok)
 }
@@ -10,10 +10,77 @@ import (
 	"github.com/alex65536/go-chess/chess"
 )
 
+type DelayKind uint8
+
+const (
+	// Simple (US) delay: the clock starts only after Delay elapses on each move.
+	DelaySimple DelayKind = iota
+	// Bronstein delay: after each move, the time used is refunded, but not more than Delay.
+	DelayBronstein
+)
+
+func (k DelayKind) String() string {
+	switch k {
+	case DelaySimple:
+		return "simple"
+	case DelayBronstein:
+		return "bronstein"
+	default:
+		return fmt.Sprintf("DelayKind(%d)", uint8(k))
+	}
+}
+
+func (k DelayKind) sep() byte {
+	if k == DelayBronstein {
+		return 'b'
+	}
+	return 'd'
+}
+
+type ControlMode uint8
+
+const (
+	// Classic control, possibly with increment and delay.
+	ModeFischer ControlMode = iota
+	// Hourglass: the time used by one side is added to the opponent's clock.
+	ModeHourglass
+	// Fixed time per move. The unused time is lost, and the clock is reset to Time after each move.
+	ModePerMove
+)
+
+func (m ControlMode) String() string {
+	switch m {
+	case ModeFischer:
+		return "fischer"
+	case ModeHourglass:
+		return "hourglass"
+	case ModePerMove:
+		return "permove"
+	default:
+		return fmt.Sprintf("ControlMode(%d)", uint8(m))
+	}
+}
+
+func (m ControlMode) prefix() string {
+	switch m {
+	case ModeHourglass:
+		return "h"
+	case ModePerMove:
+		return "s"
+	default:
+		return ""
+	}
+}
+
 type ControlItem struct {
+	Mode  ControlMode
 	Time  time.Duration
 	Inc   time.Duration
 	Moves int
+
+	// Zero means no delay.
+	Delay     time.Duration
+	DelayKind DelayKind
 }
 
 func doControlItemFromString(s string) (ControlItem, error) {
@@ -22,6 +89,14 @@ func doControlItemFromString(s string) (ControlItem, error) {
 		Inc:   0,
 		Moves: 0,
 	}
+	switch {
+	case strings.HasPrefix(s, "h"):
+		c.Mode = ModeHourglass
+		s = s[1:]
+	case strings.HasPrefix(s, "s"):
+		c.Mode = ModePerMove
+		s = s[1:]
+	}
 	if pos := strings.IndexByte(s, '/'); pos >= 0 {
 		l := s[:pos]
 		s = s[pos+1:]
@@ -32,6 +107,17 @@ func doControlItemFromString(s string) (ControlItem, error) {
 		c.Moves = int(m)
 	}
 	var err error
+	if pos := strings.IndexAny(s, "db"); pos >= 0 {
+		if s[pos] == 'b' {
+			c.DelayKind = DelayBronstein
+		}
+		r := s[pos+1:]
+		s = s[:pos]
+		c.Delay, err = parseDuration(r)
+		if err != nil {
+			return ControlItem{}, fmt.Errorf("parse delay: %w", err)
+		}
+	}
 	if pos := strings.IndexByte(s, '+'); pos >= 0 {
 		r := s[pos+1:]
 		s = s[:pos]
@@ -47,6 +133,9 @@ func doControlItemFromString(s string) (ControlItem, error) {
 	return c, nil
 }
 
+// ControlItemFromString parses the control item in the format "[moves/]time[+inc][(d|b)delay]",
+// where "d" denotes simple delay and "b" denotes Bronstein delay. Hourglass control is written as
+// "htime", and fixed time per move as "stime". All the durations are in seconds.
 func ControlItemFromString(s string, isFinal bool) (ControlItem, error) {
 	c, err := doControlItemFromString(s)
 	if err != nil {
@@ -68,6 +157,21 @@ func (c ControlItem) Validate(isFinal bool) error {
 	if c.Moves < 0 {
 		return fmt.Errorf("negative moves")
 	}
+	if c.Delay < 0 {
+		return fmt.Errorf("negative delay")
+	}
+	if c.DelayKind != DelaySimple && c.DelayKind != DelayBronstein {
+		return fmt.Errorf("bad delay kind")
+	}
+	switch c.Mode {
+	case ModeFischer:
+	case ModeHourglass, ModePerMove:
+		if c.Moves != 0 || c.Inc != 0 || c.Delay != 0 {
+			return fmt.Errorf("%v control cannot have moves, inc or delay", c.Mode)
+		}
+	default:
+		return fmt.Errorf("bad mode")
+	}
 	if !isFinal && c.Moves == 0 {
 		return fmt.Errorf("number of moves must be specified for non-final controls")
 	}
@@ -76,6 +180,7 @@ func (c ControlItem) Validate(isFinal bool) error {
 
 func (c ControlItem) String() string {
 	var b strings.Builder
+	_, _ = b.WriteString(c.Mode.prefix())
 	if c.Moves != 0 {
 		_, _ = b.WriteString(strconv.FormatInt(int64(c.Moves), 10))
 		_ = b.WriteByte('/')
@@ -85,6 +190,10 @@ func (c ControlItem) String() string {
 		_ = b.WriteByte('+')
 		_, _ = b.WriteString(formatDuration(c.Inc))
 	}
+	if c.Delay != 0 {
+		_ = b.WriteByte(c.DelayKind.sep())
+		_, _ = b.WriteString(formatDuration(c.Delay))
+	}
 	return b.String()
 }
 
@@ -129,6 +238,11 @@ func (c ControlSide) Validate() error {
 	if c[0].Time == 0 {
 		return fmt.Errorf("initial time must be positive")
 	}
+	if len(c) > 1 && slices.ContainsFunc(c, func(item ControlItem) bool {
+		return item.Mode != ModeFischer
+	}) {
+		return fmt.Errorf("hourglass and per-move controls cannot be mixed with other sections")
+	}
 	return nil
 }
 
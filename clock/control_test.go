@@ -90,6 +90,43 @@ func TestControlSideParse(t *testing.T) {
 				{Time: 640 * time.Millisecond, Inc: 12 * time.Millisecond},
 			},
 		},
+		{
+			src: "40/5400+30:900d5",
+			res: []ControlItem{
+				{Time: 90 * time.Minute, Inc: 30 * time.Second, Moves: 40},
+				{Time: 15 * time.Minute, Delay: 5 * time.Second},
+			},
+		},
+		{
+			src: "300+1b2.5",
+			res: []ControlItem{
+				{Time: 5 * time.Minute, Inc: time.Second, Delay: 2500 * time.Millisecond, DelayKind: DelayBronstein},
+			},
+		},
+		{
+			src: "h180",
+			res: []ControlItem{{Mode: ModeHourglass, Time: 3 * time.Minute}},
+		},
+		{
+			src: "s5",
+			res: []ControlItem{{Mode: ModePerMove, Time: 5 * time.Second}},
+		},
+		{
+			src: "s5+1",
+			err: "validate: section #1: permove control cannot have moves, inc or delay",
+		},
+		{
+			src: "40/60:h60",
+			err: "validate: hourglass and per-move controls cannot be mixed with other sections",
+		},
+		{
+			src: "300d",
+			err: "parse section #1: parse delay: parse integer part: strconv.ParseInt: parsing \"\": invalid syntax",
+		},
+		{
+			src: "300b-1",
+			err: "validate: section #1: negative delay",
+		},
 		{
 			src: ":20/3",
 			err: "parse section #1: parse time: parse integer part: strconv.ParseInt: parsing \"\": invalid syntax",
@@ -166,6 +203,27 @@ func TestControlFormatAndParse(t *testing.T) {
 			},
 			res: "20/3:0.64|20/3.001:0.64",
 		},
+		{
+			src: Control{
+				White: []ControlItem{{Time: time.Minute, Delay: 2 * time.Second}},
+				Black: []ControlItem{{Time: time.Minute, Delay: 2 * time.Second, DelayKind: DelayBronstein}},
+			},
+			res: "60d2|60b2",
+		},
+		{
+			src: Control{
+				White: []ControlItem{{Mode: ModeHourglass, Time: time.Minute}},
+				Black: []ControlItem{{Mode: ModeHourglass, Time: time.Minute}},
+			},
+			res: "h60",
+		},
+		{
+			src: Control{
+				White: []ControlItem{{Mode: ModePerMove, Time: 10 * time.Second}},
+				Black: []ControlItem{{Time: time.Minute, Inc: time.Second}},
+			},
+			res: "s10|60+1",
+		},
 	} {
 		err := v.src.Validate()
 		require.NoError(t, err)
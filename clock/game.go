@@ -2,6 +2,7 @@ package clock
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/alex65536/go-chess/chess"
@@ -12,12 +13,14 @@ type GameOptions struct {
 	OutcomeFilter maybe.Maybe[chess.VerdictFilter]
 	Now           func() time.Time
 	Clock         maybe.Maybe[SimpleClock]
+	Lag           maybe.Maybe[LagOptions]
 }
 
 type Game struct {
-	filter chess.VerdictFilter
-	game   *chess.Game
-	timer  *Timer
+	filter  chess.VerdictFilter
+	game    *chess.Game
+	timer   *Timer
+	history []maybe.Maybe[MoveClock]
 }
 
 func NewGame(game *chess.Game, control maybe.Maybe[Control], o GameOptions) *Game {
@@ -31,6 +34,7 @@ func NewGame(game *chess.Game, control maybe.Maybe[Control], o GameOptions) *Gam
 			Outcome:  game.Outcome(),
 			Now:      o.Now,
 			Clock:    o.Clock,
+			Lag:      o.Lag,
 		}
 		if to.Outcome.IsFinished() && to.NumFlips != 0 {
 			to.NumFlips--
@@ -38,18 +42,20 @@ func NewGame(game *chess.Game, control maybe.Maybe[Control], o GameOptions) *Gam
 		timer = NewTimer(game.StartPos().Side, control.Get(), to)
 	}
 	return &Game{
-		filter: filter,
-		game:   game,
-		timer:  timer,
+		filter:  filter,
+		game:    game,
+		timer:   timer,
+		history: make([]maybe.Maybe[MoveClock], game.Len()),
 	}
 }
 
-func (g *Game) Inner() *chess.Game     { return g.game }
-func (g *Game) CurBoard() *chess.Board { return g.game.CurBoard() }
-func (g *Game) CurSide() chess.Color   { return g.game.CurBoard().Side() }
-func (g *Game) Outcome() chess.Outcome { return g.game.Outcome() }
-func (g *Game) IsFinished() bool       { return g.game.IsFinished() }
-func (g *Game) HasTimer() bool         { return g.timer != nil }
+func (g *Game) Inner() *chess.Game          { return g.game }
+func (g *Game) CurBoard() *chess.Board      { return g.game.CurBoard() }
+func (g *Game) CurSide() chess.Color        { return g.game.CurBoard().Side() }
+func (g *Game) Outcome() chess.Outcome      { return g.game.Outcome() }
+func (g *Game) IsFinished() bool            { return g.game.IsFinished() }
+func (g *Game) HasTimer() bool              { return g.timer != nil }
+func (g *Game) Filter() chess.VerdictFilter { return g.filter }
 
 func (g *Game) UCITimeSpec() (UCITimeSpec, bool) {
 	if g.timer == nil {
@@ -58,6 +64,28 @@ func (g *Game) UCITimeSpec() (UCITimeSpec, bool) {
 	return g.timer.UCITimeSpec(), true
 }
 
+func (g *Game) ControlMode(c chess.Color) (ControlMode, bool) {
+	if g.timer == nil {
+		return ModeFischer, false
+	}
+	return g.timer.Mode(c), true
+}
+
+// UCIMovetime returns the time for the current move if the side to move has fixed time per move.
+func (g *Game) UCIMovetime() (time.Duration, bool) {
+	if g.timer == nil {
+		return 0, false
+	}
+	return g.timer.Movetime()
+}
+
+func (g *Game) LagStats(c chess.Color) (LagStats, bool) {
+	if g.timer == nil {
+		return LagStats{}, false
+	}
+	return g.timer.LagStats(c)
+}
+
 func (g *Game) Clock() (Clock, bool) {
 	if g.timer == nil {
 		return Clock{}, false
@@ -72,14 +100,97 @@ func (g *Game) Deadline() (time.Time, bool) {
 	return g.timer.Deadline()
 }
 
+func (g *Game) ClockShowsAt(d time.Duration) (time.Time, bool) {
+	if g.timer == nil {
+		return time.Time{}, false
+	}
+	return g.timer.ClockShowsAt(d)
+}
+
+// Returns the timer outcome for the current position. According to the rules, the game is drawn
+// instead of time forfeit if the opponent cannot checkmate by any legal sequence of moves.
+func (g *Game) timerOutcome() chess.Outcome {
+	o := g.timer.Outcome()
+	if o.Verdict() != chess.VerdictTimeForfeit {
+		return o
+	}
+	if winner, _ := o.Side(); !g.game.CurBoard().HasMatingMaterial(winner) {
+		return chess.MustDrawOutcome(chess.VerdictTimeoutVsNoMaterial)
+	}
+	return o
+}
+
+// Flagged returns the side whose time ran out, if the game is finished this way. The outcome is
+// then either time forfeit or a draw if the opponent cannot checkmate.
+func (g *Game) Flagged() (chess.Color, bool) {
+	if g.timer == nil {
+		return chess.ColorWhite, false
+	}
+	switch g.Outcome().Verdict() {
+	case chess.VerdictTimeForfeit, chess.VerdictTimeoutVsNoMaterial:
+	default:
+		return chess.ColorWhite, false
+	}
+	o := g.timer.Outcome()
+	if o.Verdict() != chess.VerdictTimeForfeit {
+		return chess.ColorWhite, false
+	}
+	winner, _ := o.Side()
+	return winner.Inv(), true
+}
+
 func (g *Game) UpdateTimer() {
 	if g.timer == nil || g.IsFinished() {
 		return
 	}
 	g.timer.Update()
-	if o := g.timer.Outcome(); o.IsFinished() {
-		g.game.SetOutcome(o)
+	g.applyTimerOutcome()
+}
+
+func (g *Game) applyTimerOutcome() {
+	if g.timer.Outcome().IsFinished() {
+		g.game.SetOutcome(g.timerOutcome())
+	}
+}
+
+func (g *Game) TimerPaused() bool {
+	return g.timer != nil && g.timer.Paused()
+}
+
+func (g *Game) PauseTimer() error {
+	if g.timer == nil {
+		return fmt.Errorf("no timer")
+	}
+	if g.IsFinished() {
+		return fmt.Errorf("game already finished")
+	}
+	g.timer.Pause()
+	g.applyTimerOutcome()
+	return nil
+}
+
+func (g *Game) ResumeTimer() error {
+	if g.timer == nil {
+		return fmt.Errorf("no timer")
+	}
+	if g.IsFinished() {
+		return fmt.Errorf("game already finished")
+	}
+	g.timer.Resume()
+	return nil
+}
+
+// AdjustTimer adds d to the clock of the given side, or subtracts it if d is negative.
+func (g *Game) AdjustTimer(c chess.Color, d time.Duration) error {
+	if g.timer == nil {
+		return fmt.Errorf("no timer")
+	}
+	if g.IsFinished() {
+		return fmt.Errorf("game already finished")
 	}
+	g.timer.Adjust(c, d)
+	g.applyTimerOutcome()
+	return nil
 }
 
 func (g *Game) Finish(o chess.Outcome) error {
@@ -93,13 +204,19 @@ func (g *Game) Finish(o chess.Outcome) error {
 	if g.timer != nil {
 		g.timer.Stop(o)
 		if to := g.timer.Outcome(); o != to {
-			g.game.SetOutcome(to)
+			g.game.SetOutcome(g.timerOutcome())
 		}
 	}
 	return nil
 }
 
 func (g *Game) Push(mv chess.Move) error {
+	return g.PushWithLag(mv, LagReport{})
+}
+
+// PushWithLag is like Push, but also compensates the network lag according to the report, if lag
+// compensation is enabled.
+func (g *Game) PushWithLag(mv chess.Move, r LagReport) error {
 	if g.IsFinished() {
 		return fmt.Errorf("game already finished")
 	}
@@ -107,17 +224,45 @@ func (g *Game) Push(mv chess.Move) error {
 		return fmt.Errorf("add move: %w", err)
 	}
 	g.game.SetAutoOutcome(g.filter)
-	if g.timer != nil {
-		o := g.Outcome()
-		if !o.IsFinished() {
-			g.timer.Flip()
-		} else {
-			g.timer.Stop(o)
-		}
-		if to := g.timer.Outcome(); o != to {
-			g.game.Pop()
-			g.game.SetOutcome(to)
-		}
+	if g.timer == nil {
+		g.history = append(g.history, maybe.None[MoveClock]())
+		return nil
+	}
+	side := g.timer.Side()
+	elapsed := g.timer.MoveTime()
+	o := g.Outcome()
+	if !o.IsFinished() {
+		g.timer.FlipWithLag(r)
+	} else {
+		g.timer.Stop(o)
+	}
+	if to := g.timer.Outcome(); o != to {
+		g.game.Pop()
+		g.game.SetOutcome(g.timerOutcome())
+		return nil
 	}
+	clk := g.timer.Clock()
+	g.history = append(g.history, maybe.Some(MoveClock{
+		Clock:   *clk.Side(side),
+		Elapsed: elapsed,
+	}))
 	return nil
 }
+
+// ClockHistory returns the clock information for each move in the game. It is None for the moves
+// made before the game was created or if there is no timer.
+func (g *Game) ClockHistory() []maybe.Maybe[MoveClock] {
+	return slices.Clone(g.history)
+}
+
+// ClockAnnotations returns the annotations with "%clk" and "%emt" comments after each move with
+// known clock information. They can be merged with other annotations and passed to StyledExt.
+func (g *Game) ClockAnnotations() chess.GameAnnotations {
+	a := chess.GameAnnotations{Comments: make([][]string, len(g.history)+1)}
+	for i, h := range g.history {
+		if mc, ok := h.TryGet(); ok {
+			a.Comments[i+1] = []string{mc.Comment()}
+		}
+	}
+	return a
+}
@@ -195,12 +195,63 @@ func TestGameTimeForfeitOnMate(t *testing.T) {
 	assert.Equal(t, 3, g.Inner().Len())
 	assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", g.CurBoard().FEN())
 	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite), g.Outcome())
+	flagged, ok := g.Flagged()
+	assert.True(t, ok)
+	assert.Equal(t, chess.ColorBlack, flagged)
 
 	clk, ok := g.Clock()
 	assert.True(t, ok)
 	assert.Equal(t, Clock{White: 62 * time.Second, Black: -4 * time.Second}, clk)
 }
 
+func TestGameTimeForfeitNoMaterial(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("60")
+	require.NoError(t, err)
+
+	for _, v := range []struct {
+		fen string
+		out chess.Outcome
+	}{
+		{
+			fen: "8/3k4/8/8/8/8/1K6/7Q w - - 0 1",
+			out: chess.MustDrawOutcome(chess.VerdictTimeoutVsNoMaterial),
+		},
+		{
+			fen: "8/3k4/8/8/3p4/8/1K6/8 b - - 0 1",
+			out: chess.MustDrawOutcome(chess.VerdictTimeoutVsNoMaterial),
+		},
+		{
+			fen: "8/3k4/8/8/3p4/8/1K6/7N b - - 0 1",
+			out: chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite),
+		},
+		{
+			fen: "8/3k4/8/8/8/8/1K6/7Q b - - 0 1",
+			out: chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite),
+		},
+		{
+			fen: "8/3k4/8/8/8/8/1K6/4r2N b - - 0 1",
+			out: chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite),
+		},
+	} {
+		start := now
+		b, err := chess.BoardFromFEN(v.fen)
+		require.NoError(t, err)
+		g := NewGame(chess.NewGameWithPosition(b), maybe.Some(c), GameOptions{
+			Now: func() time.Time { return now },
+		})
+		now = now.Add(61 * time.Second)
+		g.UpdateTimer()
+		assert.Equal(t, v.out, g.Outcome(), v.fen)
+		flagged, ok := g.Flagged()
+		assert.True(t, ok, v.fen)
+		assert.Equal(t, b.Side(), flagged, v.fen)
+		now = start
+	}
+}
+
 func TestGameStop(t *testing.T) {
 	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
 	require.NoError(t, err)
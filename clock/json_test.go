@@ -26,6 +26,14 @@ func TestJSON(t *testing.T) {
 			j: `"40/5+1.23"`,
 			o: mustControl("40/5+1.23"),
 		},
+		{
+			j: `"h90"`,
+			o: mustControl("h90"),
+		},
+		{
+			j: `"s2.5|40/60"`,
+			o: mustControl("s2.5|40/60"),
+		},
 	} {
 		val := reflect.New(reflect.TypeOf(v.o))
 		err := json.Unmarshal([]byte(v.j), val.Interface())
@@ -0,0 +1,142 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// LagOptions configure compensation of network lag, similar to the one on Lichess. Each player has
+// a quota, which grows with each move and is spent to compensate the lag. Thus, the players with
+// stable connection are not charged for transit time, while the time cannot be stolen by reporting
+// fake lag.
+type LagOptions struct {
+	// Quota at the start of the game.
+	//
+	// Zero means default.
+	Initial time.Duration
+
+	// Quota gained on each move.
+	//
+	// Zero means default.
+	Gain time.Duration
+
+	// Maximum quota.
+	//
+	// Zero means default.
+	Max time.Duration
+
+	// Maximum compensation for a single move.
+	//
+	// Zero means default.
+	PerMove time.Duration
+}
+
+func (o *LagOptions) FillDefaults() {
+	if o.Max == 0 {
+		o.Max = 2 * time.Second
+	}
+	if o.Initial == 0 {
+		o.Initial = min(1*time.Second, o.Max)
+	}
+	if o.Gain == 0 {
+		o.Gain = 100 * time.Millisecond
+	}
+	if o.PerMove == 0 {
+		o.PerMove = 1 * time.Second
+	}
+}
+
+func (o *LagOptions) Validate() error {
+	if o.Initial < 0 || o.Gain < 0 || o.Max < 0 || o.PerMove < 0 {
+		return fmt.Errorf("negative duration")
+	}
+	if o.Initial > o.Max {
+		return fmt.Errorf("initial quota exceeds max quota")
+	}
+	return nil
+}
+
+// LagReport describes the network conditions for a single move.
+type LagReport struct {
+	// Time spent on the move, as reported by the client. The client cannot be trusted, so it's used
+	// only together with RTT.
+	ThinkTime maybe.Maybe[time.Duration]
+
+	// Round-trip time to the client, as measured by the server.
+	RTT maybe.Maybe[time.Duration]
+}
+
+type LagStats struct {
+	// Number of moves with lag estimate.
+	Moves int
+
+	// Total and maximum estimated lag.
+	TotalLag time.Duration
+	MaxLag   time.Duration
+
+	// Total time given back to the player.
+	Compensated time.Duration
+
+	// Current quota.
+	Quota time.Duration
+}
+
+func (s LagStats) AvgLag() time.Duration {
+	if s.Moves == 0 {
+		return 0
+	}
+	return s.TotalLag / time.Duration(s.Moves)
+}
+
+type lagTracker struct {
+	o     LagOptions
+	stats [chess.ColorMax]LagStats
+}
+
+func newLagTracker(o LagOptions) *lagTracker {
+	o.FillDefaults()
+	t := &lagTracker{o: o}
+	for c := range chess.ColorMax {
+		t.stats[c].Quota = o.Initial
+	}
+	return t
+}
+
+// Returns the maximum compensation the side can get on the current move.
+func (t *lagTracker) allowance(c chess.Color) time.Duration {
+	return min(t.stats[c].Quota+t.o.Gain, t.o.Max, t.o.PerMove)
+}
+
+// Estimates the lag of the move of the given length by the report. The lag is never considered
+// larger than the round-trip time, so the client cannot get more time by reporting smaller think
+// time. Without the round-trip time, the lag is unknown.
+func estimateLag(used time.Duration, r LagReport) (time.Duration, bool) {
+	rtt, ok := r.RTT.TryGet()
+	if !ok {
+		return 0, false
+	}
+	lag := rtt
+	if think, ok := r.ThinkTime.TryGet(); ok {
+		lag = min(used-think, rtt)
+	}
+	return min(max(lag, 0), used), true
+}
+
+func (t *lagTracker) compensate(c chess.Color, used time.Duration, r LagReport) time.Duration {
+	s := &t.stats[c]
+	s.Quota = min(s.Quota+t.o.Gain, t.o.Max)
+	lag, ok := estimateLag(used, r)
+	if !ok {
+		return 0
+	}
+	comp := min(lag, s.Quota, t.o.PerMove)
+	s.Quota -= comp
+	s.Moves++
+	s.TotalLag += lag
+	s.MaxLag = max(s.MaxLag, lag)
+	s.Compensated += comp
+	return comp
+}
@@ -0,0 +1,214 @@
+package clock
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+// IterationInfo is the result of a single iteration of the engine search.
+type IterationInfo struct {
+	Depth int
+
+	// Score in centipawns from the engine's point of view. Mate scores should be mapped into large
+	// values.
+	Score int
+
+	BestMove chess.Move
+}
+
+// TimeExtension is a hook which adjusts the soft time limit. It takes the results of all the
+// iterations finished so far and returns the factor by which the soft limit is multiplied. The
+// factors of all the extensions are multiplied together.
+type TimeExtension func(iters []IterationInfo) float64
+
+// ExtendOnScoreDrop extends the time by the given factor if the score of the last iteration
+// dropped by at least threshold centipawns compared to the previous one.
+func ExtendOnScoreDrop(threshold int, factor float64) TimeExtension {
+	return func(iters []IterationInfo) float64 {
+		n := len(iters)
+		if n < 2 || iters[n-2].Score-iters[n-1].Score < threshold {
+			return 1
+		}
+		return factor
+	}
+}
+
+// ExtendOnBestMoveChange extends the time by the given factor for each change of the best move
+// within the last window iterations.
+func ExtendOnBestMoveChange(window int, factor float64) TimeExtension {
+	return func(iters []IterationInfo) float64 {
+		res := 1.0
+		for i := max(1, len(iters)-window); i < len(iters); i++ {
+			if iters[i].BestMove != iters[i-1].BestMove {
+				res *= factor
+			}
+		}
+		return res
+	}
+}
+
+type TimeManagerOptions struct {
+	// Time reserved on each move for communication with the GUI and other overhead.
+	//
+	// Zero means default.
+	Overhead time.Duration
+
+	// Expected number of moves until the end of the game, if MovesToGo is not given. It is reduced
+	// by half of the move number, but not below MinMovesHorizon.
+	//
+	// Zero means default.
+	MovesHorizon    int
+	MinMovesHorizon int
+
+	// The hard limit is the soft limit multiplied by this.
+	//
+	// Zero means default.
+	HardRatio float64
+
+	// Fraction of the time left which can be spent on a single move, even if there is only one
+	// move until the time control.
+	//
+	// Zero means default.
+	MaxFraction float64
+
+	// Maximum factor by which the extensions can increase the soft limit.
+	//
+	// Zero means default.
+	MaxExtension float64
+
+	// Hooks to adjust the soft limit after each iteration. Nil means no extensions.
+	Extensions []TimeExtension
+}
+
+func (o TimeManagerOptions) Clone() TimeManagerOptions {
+	o.Extensions = slices.Clone(o.Extensions)
+	return o
+}
+
+func (o *TimeManagerOptions) FillDefaults() {
+	if o.Overhead == 0 {
+		o.Overhead = 50 * time.Millisecond
+	}
+	if o.MovesHorizon == 0 {
+		o.MovesHorizon = 50
+	}
+	if o.MinMovesHorizon == 0 {
+		o.MinMovesHorizon = min(20, o.MovesHorizon)
+	}
+	if o.HardRatio == 0 {
+		o.HardRatio = 3
+	}
+	if o.MaxFraction == 0 {
+		o.MaxFraction = 0.5
+	}
+	if o.MaxExtension == 0 {
+		o.MaxExtension = 2
+	}
+}
+
+func (o *TimeManagerOptions) Validate() error {
+	if o.Overhead < 0 {
+		return fmt.Errorf("negative overhead")
+	}
+	if o.MovesHorizon <= 0 || o.MinMovesHorizon <= 0 {
+		return fmt.Errorf("non-positive moves horizon")
+	}
+	if o.MinMovesHorizon > o.MovesHorizon {
+		return fmt.Errorf("min moves horizon exceeds moves horizon")
+	}
+	if o.HardRatio < 1 {
+		return fmt.Errorf("hard ratio is less than one")
+	}
+	if o.MaxFraction <= 0 || o.MaxFraction > 1 {
+		return fmt.Errorf("max fraction must be in (0, 1]")
+	}
+	if o.MaxExtension < 1 {
+		return fmt.Errorf("max extension is less than one")
+	}
+	for i, e := range o.Extensions {
+		if e == nil {
+			return fmt.Errorf("extension #%v is nil", i+1)
+		}
+	}
+	return nil
+}
+
+type TimeLimits struct {
+	// The engine should not start a new iteration after the soft limit.
+	Soft time.Duration
+
+	// The engine must stop the search after the hard limit.
+	Hard time.Duration
+}
+
+// TimeManager decides how long the engine should think on a single move.
+type TimeManager struct {
+	o     TimeManagerOptions
+	base  TimeLimits
+	cur   TimeLimits
+	iters []IterationInfo
+}
+
+// NewTimeManager creates the time manager for the search in position b. Both limits are zero if
+// there is no time left after the overhead, in which case the engine must move immediately. The
+// time left may be zero or negative, as the GUI reports it this way when the engine is late.
+func NewTimeManager(b *chess.Board, spec UCITimeSpec, o TimeManagerOptions) (*TimeManager, error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate options: %w", err)
+	}
+	if spec.Winc < 0 || spec.Binc < 0 {
+		return nil, fmt.Errorf("negative increment")
+	}
+	if spec.MovesToGo < 0 {
+		return nil, fmt.Errorf("negative movestogo")
+	}
+
+	left, inc := spec.Wtime, spec.Winc
+	if b.Side() == chess.ColorBlack {
+		left, inc = spec.Btime, spec.Binc
+	}
+	left = max(left-o.Overhead, 0)
+	movesToGo := spec.MovesToGo
+	if movesToGo == 0 {
+		movesToGo = max(o.MovesHorizon-int(b.MoveNumber())/2, o.MinMovesHorizon)
+	}
+
+	limit := time.Duration(float64(left) * o.MaxFraction)
+	soft := min(left/time.Duration(movesToGo)+inc, limit)
+	hard := min(time.Duration(float64(soft)*o.HardRatio), limit)
+	m := &TimeManager{
+		o:    o,
+		base: TimeLimits{Soft: soft, Hard: hard},
+	}
+	m.cur = m.base
+	return m, nil
+}
+
+func (m *TimeManager) Limits() TimeLimits { return m.cur }
+
+// AddIteration records the iteration result and recalculates the soft limit using the
+// extensions. The soft limit never exceeds the hard one.
+func (m *TimeManager) AddIteration(info IterationInfo) {
+	m.iters = append(m.iters, info)
+	factor := 1.0
+	for _, e := range m.o.Extensions {
+		factor *= max(e(m.iters), 0)
+	}
+	factor = min(factor, m.o.MaxExtension)
+	m.cur.Soft = min(time.Duration(float64(m.base.Soft)*factor), m.base.Hard)
+}
+
+// CanStartIteration reports whether a new iteration should be started after elapsed time.
+func (m *TimeManager) CanStartIteration(elapsed time.Duration) bool {
+	return elapsed < m.cur.Soft
+}
+
+// MustStop reports whether the search must be stopped after elapsed time.
+func (m *TimeManager) MustStop(elapsed time.Duration) bool {
+	return elapsed >= m.cur.Hard
+}
@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeManagerLimits(t *testing.T) {
+	b := chess.InitialBoard()
+	spec := UCITimeSpec{Wtime: 51 * time.Second, Btime: 31 * time.Second, Winc: time.Second}
+	o := TimeManagerOptions{Overhead: time.Second}
+
+	m, err := NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{Soft: 2 * time.Second, Hard: 6 * time.Second}, m.Limits())
+	assert.True(t, m.CanStartIteration(1999*time.Millisecond))
+	assert.False(t, m.CanStartIteration(2*time.Second))
+	assert.False(t, m.MustStop(5*time.Second))
+	assert.True(t, m.MustStop(6*time.Second))
+
+	b, err = chess.BoardFromFEN("4k3/8/8/8/8/8/4P3/4K3 b - - 0 40")
+	require.NoError(t, err)
+	m, err = NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{Soft: time.Second, Hard: 3 * time.Second}, m.Limits())
+
+	spec.MovesToGo = 1
+	m, err = NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{Soft: 15 * time.Second, Hard: 15 * time.Second}, m.Limits())
+
+	spec.MovesToGo = 10
+	m, err = NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{Soft: 3 * time.Second, Hard: 9 * time.Second}, m.Limits())
+
+	spec.Btime = 500 * time.Millisecond
+	m, err = NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{}, m.Limits())
+	assert.True(t, m.MustStop(0))
+
+	spec.Btime = -time.Second
+	m, err = NewTimeManager(b, spec, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{}, m.Limits())
+	m, err = NewTimeManager(b, UCITimeSpec{}, o)
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{}, m.Limits())
+
+	_, err = NewTimeManager(b, UCITimeSpec{Binc: -time.Second}, o)
+	assert.Error(t, err)
+	_, err = NewTimeManager(b, UCITimeSpec{MovesToGo: -1}, o)
+	assert.Error(t, err)
+	_, err = NewTimeManager(b, spec, TimeManagerOptions{HardRatio: 0.5})
+	assert.Error(t, err)
+}
+
+func TestTimeManagerExtensions(t *testing.T) {
+	b := chess.InitialBoard()
+	spec := UCITimeSpec{Wtime: 51 * time.Second, Btime: 51 * time.Second}
+	m, err := NewTimeManager(b, spec, TimeManagerOptions{
+		Overhead:     time.Second,
+		MaxExtension: 2.5,
+		Extensions: []TimeExtension{
+			ExtendOnScoreDrop(50, 1.5),
+			ExtendOnBestMoveChange(2, 2),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TimeLimits{Soft: time.Second, Hard: 3 * time.Second}, m.Limits())
+
+	e4, err := chess.LegalMoveFromUCI("e2e4", b)
+	require.NoError(t, err)
+	d4, err := chess.LegalMoveFromUCI("d2d4", b)
+	require.NoError(t, err)
+
+	m.AddIteration(IterationInfo{Depth: 1, Score: 30, BestMove: e4})
+	assert.Equal(t, time.Second, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 2, Score: 20, BestMove: e4})
+	assert.Equal(t, time.Second, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 3, Score: -40, BestMove: e4})
+	assert.Equal(t, 1500*time.Millisecond, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 4, Score: -40, BestMove: d4})
+	assert.Equal(t, 2*time.Second, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 5, Score: -100, BestMove: e4})
+	assert.Equal(t, 2500*time.Millisecond, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 6, Score: -100, BestMove: e4})
+	assert.Equal(t, 2*time.Second, m.Limits().Soft)
+	m.AddIteration(IterationInfo{Depth: 7, Score: -100, BestMove: e4})
+	assert.Equal(t, time.Second, m.Limits().Soft)
+	assert.Equal(t, 3*time.Second, m.Limits().Hard)
+}
@@ -0,0 +1,137 @@
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// ControlFromPGN parses the value of PGN TimeControl tag. It returns None for "-", which means no
+// time control. Unknown time control "?" is reported as error.
+//
+// The sections are separated by ':' and may have the form "moves/seconds", "seconds",
+// "seconds+inc" or "*seconds", the latter meaning sandclock (i.e. hourglass). As an extension,
+// increment is also allowed with the number of moves, like "40/5400+30".
+func ControlFromPGN(s string) (maybe.Maybe[Control], error) {
+	switch s {
+	case "-":
+		return maybe.None[Control](), nil
+	case "?":
+		return maybe.None[Control](), fmt.Errorf("unknown time control")
+	case "":
+		return maybe.None[Control](), fmt.Errorf("empty string")
+	}
+	spl := strings.Split(s, ":")
+	side := make(ControlSide, len(spl))
+	for i, sub := range spl {
+		mode := ModeFischer
+		if rest, ok := strings.CutPrefix(sub, "*"); ok {
+			mode = ModeHourglass
+			sub = rest
+		}
+		if strings.ContainsAny(sub, "hsdb.") {
+			// Such sections are valid in our own syntax, but not in PGN.
+			return maybe.None[Control](), fmt.Errorf("section #%v: bad syntax", i+1)
+		}
+		item, err := doControlItemFromString(sub)
+		if err != nil {
+			return maybe.None[Control](), fmt.Errorf("parse section #%v: %w", i+1, err)
+		}
+		item.Mode = mode
+		side[i] = item
+	}
+	if err := side.Validate(); err != nil {
+		return maybe.None[Control](), fmt.Errorf("validate: %w", err)
+	}
+	return maybe.Some(Control{White: side, Black: side.Clone()}), nil
+}
+
+// ControlToPGN converts the time control into the value of PGN TimeControl tag. None is converted
+// into "-". Controls which cannot be expressed in PGN, like delays, fixed time per move, fractional
+// seconds or different controls for White and Black, are reported as error.
+func ControlToPGN(mc maybe.Maybe[Control]) (string, error) {
+	c, ok := mc.TryGet()
+	if !ok {
+		return "-", nil
+	}
+	if !c.White.Eq(c.Black) {
+		return "", fmt.Errorf("different controls for white and black")
+	}
+	var b strings.Builder
+	for i, item := range c.White {
+		if i != 0 {
+			_ = b.WriteByte(':')
+		}
+		if item.Delay != 0 {
+			return "", fmt.Errorf("section #%v: delay is not supported", i+1)
+		}
+		if item.Time%time.Second != 0 || item.Inc%time.Second != 0 {
+			return "", fmt.Errorf("section #%v: fractional seconds are not supported", i+1)
+		}
+		switch item.Mode {
+		case ModeFischer:
+		case ModeHourglass:
+			_ = b.WriteByte('*')
+		default:
+			return "", fmt.Errorf("section #%v: %v control is not supported", i+1, item.Mode)
+		}
+		item.Mode = ModeFischer
+		_, _ = b.WriteString(item.String())
+	}
+	return b.String(), nil
+}
+
+// FormatPGNClock formats the duration as in "%clk" and "%emt" PGN comments, i.e. "H:MM:SS". The
+// tenths of second are added only if the duration is not a whole number of seconds. Negative
+// durations are formatted as zero.
+func FormatPGNClock(d time.Duration) string {
+	d = max(d, 0)
+	secs := int64(d / time.Second)
+	s := fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	if tenths := int64(d%time.Second) / int64(100*time.Millisecond); tenths != 0 {
+		s += "." + strconv.FormatInt(tenths, 10)
+	}
+	return s
+}
+
+// ParsePGNClock parses the duration in "H:MM:SS[.f]" format, as in "%clk" and "%emt" PGN
+// comments.
+func ParsePGNClock(s string) (time.Duration, error) {
+	spl := strings.Split(s, ":")
+	if len(spl) != 3 {
+		return 0, fmt.Errorf("bad clock format")
+	}
+	h, err := strconv.ParseUint(spl[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse hours: %w", err)
+	}
+	m, err := strconv.ParseUint(spl[1], 10, 8)
+	if err != nil || m >= 60 {
+		return 0, fmt.Errorf("bad minutes")
+	}
+	secs, err := parseDuration(spl[2])
+	if err != nil {
+		return 0, fmt.Errorf("parse seconds: %w", err)
+	}
+	if secs < 0 || secs >= time.Minute {
+		return 0, fmt.Errorf("bad seconds")
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + secs, nil
+}
+
+// MoveClock is the clock information recorded for a single move.
+type MoveClock struct {
+	// Time left on the clock of the side which made the move, after the move.
+	Clock time.Duration
+
+	// Time spent on the move.
+	Elapsed time.Duration
+}
+
+// Comment returns the PGN comment with "%clk" and "%emt" commands.
+func (m MoveClock) Comment() string {
+	return "[%clk " + FormatPGNClock(m.Clock) + "] [%emt " + FormatPGNClock(m.Elapsed) + "]"
+}
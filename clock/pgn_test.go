@@ -0,0 +1,138 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlPGN(t *testing.T) {
+	for _, v := range []struct {
+		src string
+		res maybe.Maybe[Control]
+		out string
+		err string
+	}{
+		{
+			src: "40/7200:3600",
+			res: maybe.Some(Control{
+				White: ControlSide{{Time: 2 * time.Hour, Moves: 40}, {Time: time.Hour}},
+				Black: ControlSide{{Time: 2 * time.Hour, Moves: 40}, {Time: time.Hour}},
+			}),
+		},
+		{
+			src: "300+2",
+			res: maybe.Some(Control{
+				White: ControlSide{{Time: 5 * time.Minute, Inc: 2 * time.Second}},
+				Black: ControlSide{{Time: 5 * time.Minute, Inc: 2 * time.Second}},
+			}),
+		},
+		{
+			src: "*180",
+			res: maybe.Some(Control{
+				White: ControlSide{{Mode: ModeHourglass, Time: 3 * time.Minute}},
+				Black: ControlSide{{Mode: ModeHourglass, Time: 3 * time.Minute}},
+			}),
+		},
+		{
+			src: "-",
+			res: maybe.None[Control](),
+		},
+		{src: "?", err: "unknown time control"},
+		{src: "h180", err: "section #1: bad syntax"},
+		{src: "300d2", err: "section #1: bad syntax"},
+		{src: "0.5+1", err: "section #1: bad syntax"},
+		{src: "40/7200:*60", err: "validate: hourglass and per-move controls cannot be mixed with other sections"},
+	} {
+		c, err := ControlFromPGN(v.src)
+		if v.err != "" {
+			assert.EqualError(t, err, v.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, v.res, c)
+		s, err := ControlToPGN(c)
+		require.NoError(t, err)
+		assert.Equal(t, v.src, s)
+	}
+
+	for _, v := range []struct {
+		src string
+		err string
+	}{
+		{src: "60d2", err: "section #1: delay is not supported"},
+		{src: "s5", err: "section #1: permove control is not supported"},
+		{src: "60|30", err: "different controls for white and black"},
+		{src: "0.5+1", err: "section #1: fractional seconds are not supported"},
+		{src: "40/5400:60+0.5", err: "section #2: fractional seconds are not supported"},
+	} {
+		c, err := ControlFromString(v.src)
+		require.NoError(t, err)
+		_, err = ControlToPGN(maybe.Some(c))
+		assert.EqualError(t, err, v.err)
+	}
+}
+
+func TestPGNClock(t *testing.T) {
+	for _, v := range []struct {
+		d time.Duration
+		s string
+	}{
+		{d: 0, s: "0:00:00"},
+		{d: 5*time.Minute + 3*time.Second, s: "0:05:03"},
+		{d: 2*time.Hour + 1500*time.Millisecond, s: "2:00:01.5"},
+	} {
+		assert.Equal(t, v.s, FormatPGNClock(v.d))
+		d, err := ParsePGNClock(v.s)
+		require.NoError(t, err)
+		assert.Equal(t, v.d, d)
+	}
+	assert.Equal(t, "0:00:00", FormatPGNClock(-time.Second))
+
+	for _, s := range []string{"", "1:00", "0:60:00", "0:00:60", "a:00:00"} {
+		_, err := ParsePGNClock(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestGameClockHistory(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("300+2")
+	require.NoError(t, err)
+
+	g := NewGame(chess.NewGame(), maybe.Some(c), GameOptions{
+		Now: func() time.Time { return now },
+	})
+	for i, s := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		now = now.Add(time.Duration(i+1) * time.Second)
+		mv, err := chess.MoveFromUCI(s, g.CurBoard())
+		require.NoError(t, err)
+		require.NoError(t, g.Push(mv))
+	}
+	require.True(t, g.IsFinished())
+
+	assert.Equal(t, []maybe.Maybe[MoveClock]{
+		maybe.Some(MoveClock{Clock: 301 * time.Second, Elapsed: 1 * time.Second}),
+		maybe.Some(MoveClock{Clock: 300 * time.Second, Elapsed: 2 * time.Second}),
+		maybe.Some(MoveClock{Clock: 300 * time.Second, Elapsed: 3 * time.Second}),
+		maybe.Some(MoveClock{Clock: 296 * time.Second, Elapsed: 4 * time.Second}),
+	}, g.ClockHistory())
+
+	s, err := g.Inner().StyledExt(chess.GameStyle{
+		Move:       chess.MoveStyleSAN,
+		MoveNumber: chess.MoveNumberStyle{Enabled: true},
+	}, g.ClockAnnotations())
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"1. f3 {[%clk 0:05:01] [%emt 0:00:01]} 1... e5 {[%clk 0:05:00] [%emt 0:00:02]} "+
+			"2. g4 {[%clk 0:05:00] [%emt 0:00:03]} 2... Qh4# {[%clk 0:04:56] [%emt 0:00:04]}",
+		s,
+	)
+}
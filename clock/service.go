@@ -0,0 +1,378 @@
+package clock
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+type EventKind uint8
+
+const (
+	EventTick EventKind = iota
+	EventSideSwitch
+	EventLowTime
+	EventFlag
+	EventFinish
+	EventPause
+	EventResume
+	EventAdjust
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventTick:
+		return "tick"
+	case EventSideSwitch:
+		return "side switch"
+	case EventLowTime:
+		return "low time"
+	case EventFlag:
+		return "flag"
+	case EventFinish:
+		return "finish"
+	case EventPause:
+		return "pause"
+	case EventResume:
+		return "resume"
+	case EventAdjust:
+		return "adjust"
+	default:
+		return fmt.Sprintf("EventKind(%d)", uint8(k))
+	}
+}
+
+type Event struct {
+	Kind EventKind
+
+	// Side to move for EventSideSwitch, the side which is low on time for EventLowTime, the side
+	// which flagged for EventFlag, and the side whose clock changed for EventAdjust.
+	Side chess.Color
+
+	// Clock at the moment of the event.
+	Clock Clock
+
+	// Threshold which was crossed, for EventLowTime.
+	Threshold time.Duration
+
+	// Outcome of the game, for EventFlag and EventFinish.
+	Outcome chess.Outcome
+}
+
+type ServiceOptions struct {
+	// Low time warnings are sent when the clock goes below each of these thresholds. Each warning
+	// is sent once, unless the clock goes above the threshold again.
+	LowTime []time.Duration
+
+	// Interval between EventTick events. Zero means no ticks.
+	Tick time.Duration
+}
+
+func (o ServiceOptions) Clone() ServiceOptions {
+	o.LowTime = slices.Clone(o.LowTime)
+	return o
+}
+
+func (o *ServiceOptions) Validate() error {
+	for _, t := range o.LowTime {
+		if t <= 0 {
+			return fmt.Errorf("non-positive low time threshold")
+		}
+	}
+	if o.Tick < 0 {
+		return fmt.Errorf("negative tick")
+	}
+	return nil
+}
+
+// Service runs the clock of the game in background and sends the events about it. The game must
+// have a timer which uses real time.
+//
+// The service owns the game, so it must be only accessed via the service methods.
+type Service struct {
+	o      ServiceOptions
+	events chan Event
+	wake   chan struct{}
+	cancel func()
+	done   chan struct{}
+
+	mu       sync.Mutex
+	g        *Game
+	side     chess.Color
+	finished bool
+	warned   [chess.ColorMax]int
+	nextTick time.Time
+	pending  []Event
+}
+
+func NewService(ctx context.Context, g *Game, o ServiceOptions) (*Service, error) {
+	o = o.Clone()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	if !g.HasTimer() {
+		return nil, fmt.Errorf("game has no timer")
+	}
+	slices.SortFunc(o.LowTime, func(a, b time.Duration) int {
+		// Descending order, so the thresholds are crossed one by one.
+		return cmp.Compare(b, a)
+	})
+	o.LowTime = slices.Compact(o.LowTime)
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Service{
+		o:      o,
+		events: make(chan Event),
+		wake:   make(chan struct{}, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		g:      g,
+		side:   g.CurSide(),
+	}
+	if o.Tick != 0 {
+		s.nextTick = time.Now().Add(o.Tick)
+	}
+	s.mu.Lock()
+	s.update()
+	s.mu.Unlock()
+	go s.loop(ctx)
+	return s, nil
+}
+
+// Events returns the channel with events. It is closed after the service is shut down.
+//
+// The events are queued until received, except for EventTick, of which only the latest one is
+// kept. Other events are caused either by the calls to the service or by the clock, and the clock
+// generates a bounded number of them per move. So, the queue grows only if the consumer doesn't
+// read the events while moves are being made.
+func (s *Service) Events() <-chan Event { return s.events }
+
+func (s *Service) Done() <-chan struct{} { return s.done }
+
+// Close shuts down the service and waits until it terminates. The events which are not received
+// yet are dropped.
+func (s *Service) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) emit(e Event) {
+	if e.Kind == EventTick {
+		// Only the latest tick is kept, so the ticks don't pile up if the consumer is slow. The
+		// first pending event may be being sent now, so it's never removed.
+		if len(s.pending) > 1 {
+			s.pending = append(s.pending[:1], slices.DeleteFunc(s.pending[1:], func(e Event) bool {
+				return e.Kind == EventTick
+			})...)
+		}
+	}
+	s.pending = append(s.pending, e)
+}
+
+func (s *Service) clock() Clock {
+	c, _ := s.g.Clock()
+	return c
+}
+
+// Updates the timer and generates all the events which happened since the last update. Must be
+// called with mu held.
+func (s *Service) update() {
+	if s.finished {
+		return
+	}
+	s.g.UpdateTimer()
+	c := s.clock()
+
+	if side := s.g.CurSide(); side != s.side {
+		s.side = side
+		if !s.g.IsFinished() {
+			s.emit(Event{Kind: EventSideSwitch, Side: side, Clock: c})
+		}
+	}
+
+	for col := range chess.ColorMax {
+		level := 0
+		for level < len(s.o.LowTime) && *c.Side(col) <= s.o.LowTime[level] {
+			level++
+		}
+		for ; s.warned[col] < level; s.warned[col]++ {
+			s.emit(Event{
+				Kind:      EventLowTime,
+				Side:      col,
+				Clock:     c,
+				Threshold: s.o.LowTime[s.warned[col]],
+			})
+		}
+		s.warned[col] = level
+	}
+
+	if o := s.g.Outcome(); o.IsFinished() {
+		s.finished = true
+		if flagged, ok := s.g.Flagged(); ok {
+			s.emit(Event{Kind: EventFlag, Side: flagged, Clock: c, Outcome: o})
+		} else {
+			s.emit(Event{Kind: EventFinish, Side: s.side, Clock: c, Outcome: o})
+		}
+		return
+	}
+
+	if s.o.Tick != 0 && !s.g.TimerPaused() {
+		if now := time.Now(); !now.Before(s.nextTick) {
+			s.emit(Event{Kind: EventTick, Side: s.side, Clock: c})
+			s.nextTick = now.Add(s.o.Tick)
+		}
+	}
+}
+
+// Returns the moment when the next update is needed. Must be called with mu held.
+func (s *Service) nextWake() (time.Time, bool) {
+	if s.finished {
+		return time.Time{}, false
+	}
+	dl, ok := s.g.Deadline()
+	if !ok {
+		return time.Time{}, false
+	}
+	wake := dl
+	if w := s.warned[s.side]; w < len(s.o.LowTime) {
+		if low, ok := s.g.ClockShowsAt(s.o.LowTime[w]); ok && low.Before(wake) {
+			wake = low
+		}
+	}
+	if s.o.Tick != 0 && s.nextTick.Before(wake) {
+		wake = s.nextTick
+	}
+	return wake, true
+}
+
+func (s *Service) loop(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.events)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		s.update()
+		wake, hasWake := s.nextWake()
+		var (
+			out chan<- Event
+			e   Event
+		)
+		if len(s.pending) != 0 {
+			out = s.events
+			e = s.pending[0]
+		}
+		s.mu.Unlock()
+
+		var timerC <-chan time.Time
+		if hasWake {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(max(0, time.Until(wake)))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timerC:
+		case out <- e:
+			s.mu.Lock()
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Do runs f on the game, and then sends the events caused by it. The game must not be used after f
+// returns.
+func (s *Service) Do(f func(g *Game) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notify()
+	err := f(s.g)
+	s.update()
+	return err
+}
+
+func (s *Service) Push(mv chess.Move) error {
+	return s.Do(func(g *Game) error { return g.Push(mv) })
+}
+
+func (s *Service) PushWithLag(mv chess.Move, r LagReport) error {
+	return s.Do(func(g *Game) error { return g.PushWithLag(mv, r) })
+}
+
+func (s *Service) Finish(o chess.Outcome) error {
+	return s.Do(func(g *Game) error { return g.Finish(o) })
+}
+
+func (s *Service) Clock() Clock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clock()
+}
+
+func (s *Service) Outcome() chess.Outcome {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g.Outcome()
+}
+
+func (s *Service) Pause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notify()
+	err := s.g.PauseTimer()
+	s.update()
+	if err != nil {
+		return err
+	}
+	if !s.finished {
+		s.emit(Event{Kind: EventPause, Side: s.side, Clock: s.clock()})
+	}
+	return nil
+}
+
+func (s *Service) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notify()
+	if err := s.g.ResumeTimer(); err != nil {
+		return err
+	}
+	s.emit(Event{Kind: EventResume, Side: s.side, Clock: s.clock()})
+	return nil
+}
+
+// Adjust adds d to the clock of the given side, or subtracts it if d is negative.
+func (s *Service) Adjust(c chess.Color, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notify()
+	if err := s.g.AdjustTimer(c, d); err != nil {
+		return err
+	}
+	s.emit(Event{Kind: EventAdjust, Side: c, Clock: s.clock()})
+	s.update()
+	return nil
+}
@@ -0,0 +1,117 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextEvent(t *testing.T, s *Service) Event {
+	select {
+	case e, ok := <-s.Events():
+		require.True(t, ok)
+		return e
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no event")
+		return Event{}
+	}
+}
+
+func TestServiceFlag(t *testing.T) {
+	c, err := ControlFromString("0.3")
+	require.NoError(t, err)
+	g := NewGame(chess.NewGame(), maybe.Some(c), GameOptions{})
+
+	start := time.Now()
+	s, err := NewService(context.Background(), g, ServiceOptions{
+		LowTime: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	e := nextEvent(t, s)
+	assert.Equal(t, EventLowTime, e.Kind)
+	assert.Equal(t, chess.ColorWhite, e.Side)
+	assert.Equal(t, 200*time.Millisecond, e.Threshold)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	e = nextEvent(t, s)
+	assert.Equal(t, EventLowTime, e.Kind)
+	assert.Equal(t, 100*time.Millisecond, e.Threshold)
+
+	e = nextEvent(t, s)
+	assert.Equal(t, EventFlag, e.Kind)
+	assert.Equal(t, chess.ColorWhite, e.Side)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), e.Outcome)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.Equal(t, e.Outcome, s.Outcome())
+}
+
+func TestServiceControl(t *testing.T) {
+	c, err := ControlFromString("60")
+	require.NoError(t, err)
+	g := NewGame(chess.NewGame(), maybe.Some(c), GameOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := NewService(ctx, g, ServiceOptions{LowTime: []time.Duration{30 * time.Second}})
+	require.NoError(t, err)
+
+	mv, err := chess.MoveFromUCI("e2e4", chess.InitialBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Push(mv))
+	e := nextEvent(t, s)
+	assert.Equal(t, EventSideSwitch, e.Kind)
+	assert.Equal(t, chess.ColorBlack, e.Side)
+	assert.True(t, e.Clock.BlackTicking)
+
+	require.NoError(t, s.Pause())
+	e = nextEvent(t, s)
+	assert.Equal(t, EventPause, e.Kind)
+	assert.False(t, e.Clock.BlackTicking)
+	assert.InDelta(t, 60*time.Second, s.Clock().Black, float64(time.Second))
+	require.NoError(t, s.Resume())
+	assert.Equal(t, EventResume, nextEvent(t, s).Kind)
+
+	require.NoError(t, s.Adjust(chess.ColorWhite, -40*time.Second))
+	e = nextEvent(t, s)
+	assert.Equal(t, EventAdjust, e.Kind)
+	assert.Equal(t, chess.ColorWhite, e.Side)
+	e = nextEvent(t, s)
+	assert.Equal(t, EventLowTime, e.Kind)
+	assert.Equal(t, chess.ColorWhite, e.Side)
+
+	require.NoError(t, s.Adjust(chess.ColorBlack, -61*time.Second))
+	assert.Equal(t, EventAdjust, nextEvent(t, s).Kind)
+	e = nextEvent(t, s)
+	assert.Equal(t, EventLowTime, e.Kind)
+	assert.Equal(t, chess.ColorBlack, e.Side)
+	e = nextEvent(t, s)
+	assert.Equal(t, EventFlag, e.Kind)
+	assert.Equal(t, chess.ColorBlack, e.Side)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite), e.Outcome)
+	assert.Error(t, s.Adjust(chess.ColorBlack, time.Second))
+
+	cancel()
+	<-s.Done()
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+}
+
+func TestServiceCoalesceTicks(t *testing.T) {
+	s := &Service{}
+	s.emit(Event{Kind: EventTick, Side: chess.ColorWhite})
+	s.emit(Event{Kind: EventTick, Side: chess.ColorWhite})
+	s.emit(Event{Kind: EventSideSwitch, Side: chess.ColorBlack})
+	s.emit(Event{Kind: EventTick, Side: chess.ColorBlack})
+	s.emit(Event{Kind: EventTick, Side: chess.ColorBlack})
+	assert.Equal(t, []Event{
+		{Kind: EventTick, Side: chess.ColorWhite},
+		{Kind: EventSideSwitch, Side: chess.ColorBlack},
+		{Kind: EventTick, Side: chess.ColorBlack},
+	}, s.pending)
+}
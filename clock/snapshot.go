@@ -0,0 +1,297 @@
+package clock
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// TimerSnapshot is the full state of the timer. It can be encoded with encoding/json or
+// encoding/gob.
+type TimerSnapshot struct {
+	Control Control
+	Side    chess.Color
+	Clock   SimpleClock
+
+	// Number of control items already passed by each side.
+	Stage [chess.ColorMax]int
+
+	// Number of moves left until the next control item for each side. Zero means that the current
+	// control item has no move limit.
+	MovesLeft [chess.ColorMax]int
+
+	// Time used on the current move before the snapshot was taken.
+	Used time.Duration
+
+	// Moment at which the snapshot was taken. On restore, the time elapsed since this moment is
+	// charged from the running clock. Pause the timer before taking the snapshot to avoid this,
+	// e.g. when the game is adjourned.
+	Time time.Time
+
+	Paused bool
+
+	Verdict chess.Verdict
+	Winner  chess.Color
+
+	// Nil if lag compensation is disabled.
+	Lag      *LagOptions
+	LagStats [chess.ColorMax]LagStats
+}
+
+// Snapshot updates the timer and returns its state.
+func (t *Timer) Snapshot() TimerSnapshot {
+	t.Update()
+	winner, _ := t.outcome.Side()
+	s := TimerSnapshot{
+		Control: t.ctrl.control.Clone(),
+		Side:    t.side,
+		Clock:   t.clock,
+		Used:    t.used,
+		Time:    t.cur,
+		Paused:  t.paused,
+		Verdict: t.outcome.Verdict(),
+		Winner:  winner,
+	}
+	for c := range chess.ColorMax {
+		s.Stage[c] = t.ctrl.sub[c].stage
+		s.MovesLeft[c] = t.ctrl.sub[c].left
+	}
+	if t.lag != nil {
+		lo := t.lag.o
+		s.Lag = &lo
+		s.LagStats = t.lag.stats
+	}
+	return s
+}
+
+func (s *TimerSnapshot) Validate() error {
+	if err := s.Control.Validate(); err != nil {
+		return fmt.Errorf("control: %w", err)
+	}
+	if !s.Side.IsValid() || !s.Winner.IsValid() {
+		return fmt.Errorf("bad color")
+	}
+	if !s.Verdict.IsValid() {
+		return fmt.Errorf("bad verdict")
+	}
+	for c := range chess.ColorMax {
+		side := *s.Control.Side(c)
+		if s.Stage[c] < 0 || s.Stage[c] >= len(side) {
+			return fmt.Errorf("bad stage for %v", c.LongString())
+		}
+		moves := side[s.Stage[c]].Moves
+		if s.MovesLeft[c] < 0 || s.MovesLeft[c] > moves || (moves != 0 && s.MovesLeft[c] == 0) {
+			return fmt.Errorf("bad moves left for %v", c.LongString())
+		}
+	}
+	if s.Used < 0 {
+		return fmt.Errorf("negative used time")
+	}
+	if s.Lag != nil {
+		if err := s.Lag.Validate(); err != nil {
+			return fmt.Errorf("lag: %w", err)
+		}
+	}
+	return nil
+}
+
+// TimerFromSnapshot restores the timer from the snapshot. If now is nil, then time.Now is used.
+func TimerFromSnapshot(s TimerSnapshot, now func() time.Time) (*Timer, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	t := &Timer{
+		side:    s.Side,
+		outcome: chess.NewOutcome(s.Verdict, s.Winner),
+		cur:     s.Time,
+		nowFn:   now,
+		clock:   s.Clock,
+		used:    s.Used,
+		paused:  s.Paused,
+	}
+	t.ctrl.control = s.Control.Clone()
+	for c := range chess.ColorMax {
+		sub := &t.ctrl.sub[c]
+		sub.control = (*s.Control.Side(c))[s.Stage[c]:].Clone()
+		sub.left = s.MovesLeft[c]
+		sub.stage = s.Stage[c]
+	}
+	if s.Lag != nil {
+		t.lag = newLagTracker(*s.Lag)
+		t.lag.stats = s.LagStats
+	}
+	t.Update()
+	return t, nil
+}
+
+// Snapshot is the full state of the game with clock. It can be encoded with encoding/json or
+// encoding/gob, and also implements encoding.BinaryMarshaler.
+type Snapshot struct {
+	Start   chess.RawBoard
+	Moves   []string
+	Verdict chess.Verdict
+	Winner  chess.Color
+	Filter  chess.VerdictFilter
+
+	// Nil if the game has no timer.
+	Timer *TimerSnapshot
+
+	// Clock information for the last moves of the game. The moves before them have no clock
+	// information, see Game.ClockHistory.
+	History []MoveClock
+}
+
+// TimerSnapshot updates the timer and returns its state. It returns false if there is no timer.
+func (g *Game) TimerSnapshot() (TimerSnapshot, bool) {
+	if g.timer == nil {
+		return TimerSnapshot{}, false
+	}
+	g.UpdateTimer()
+	return g.timer.Snapshot(), true
+}
+
+// Snapshot updates the timer and returns the state of the game.
+func (g *Game) Snapshot() Snapshot {
+	g.UpdateTimer()
+	o := g.Outcome()
+	winner, _ := o.Side()
+	s := Snapshot{
+		Start:   g.game.StartPos(),
+		Moves:   make([]string, g.game.Len()),
+		Verdict: o.Verdict(),
+		Winner:  winner,
+		Filter:  g.filter,
+	}
+	for i := range g.game.Len() {
+		s.Moves[i] = g.game.MoveAt(i).UCI()
+	}
+	if g.timer != nil {
+		ts := g.timer.Snapshot()
+		s.Timer = &ts
+	}
+	for _, h := range g.history {
+		if mc, ok := h.TryGet(); ok {
+			s.History = append(s.History, mc)
+		}
+	}
+	return s
+}
+
+// GameFromSnapshot restores the game from the snapshot. Only Now is used from the options, and
+// the rest is taken from the snapshot.
+func GameFromSnapshot(s Snapshot, o GameOptions) (*Game, error) {
+	if !s.Verdict.IsValid() {
+		return nil, fmt.Errorf("bad verdict")
+	}
+	if !s.Filter.IsValid() {
+		return nil, fmt.Errorf("bad filter")
+	}
+	b, err := chess.NewBoard(s.Start)
+	if err != nil {
+		return nil, fmt.Errorf("bad start position: %w", err)
+	}
+	game := chess.NewGameWithPosition(b)
+	for i, str := range s.Moves {
+		mv, err := chess.LegalMoveFromUCI(str, game.CurBoard())
+		if err != nil {
+			return nil, fmt.Errorf("bad move #%v: %w", i+1, err)
+		}
+		game.PushLegalMove(mv)
+	}
+	if !s.Winner.IsValid() {
+		return nil, fmt.Errorf("bad winner")
+	}
+	outcome := chess.NewOutcome(s.Verdict, s.Winner)
+	if err := checkOutcome(game, outcome, s.Filter); err != nil {
+		return nil, fmt.Errorf("bad outcome: %w", err)
+	}
+	game.SetOutcome(outcome)
+	if len(s.History) > game.Len() {
+		return nil, fmt.Errorf("history is too long")
+	}
+
+	g := &Game{
+		filter:  s.Filter,
+		game:    game,
+		history: make([]maybe.Maybe[MoveClock], game.Len()),
+	}
+	offset := game.Len() - len(s.History)
+	for i, mc := range s.History {
+		g.history[offset+i] = maybe.Some(mc)
+	}
+	if s.Timer != nil {
+		if !game.IsFinished() && s.Timer.Side != game.CurBoard().Side() {
+			return nil, fmt.Errorf("timer side doesn't match the side to move")
+		}
+		g.timer, err = TimerFromSnapshot(*s.Timer, o.Now)
+		if err != nil {
+			return nil, fmt.Errorf("timer: %w", err)
+		}
+		g.UpdateTimer()
+	}
+	return g, nil
+}
+
+// Checks that the outcome doesn't contradict the final position of the game.
+func checkOutcome(g *chess.Game, o chess.Outcome, filter chess.VerdictFilter) error {
+	b := g.CurBoard()
+	calc := g.CalcOutcome()
+	if !b.HasLegalMoves() && o != calc {
+		return fmt.Errorf("game must be finished with %v", calc)
+	}
+	if o.Verdict() == chess.VerdictRunning {
+		if calc.IsFinished() && calc.Passes(filter) {
+			return fmt.Errorf("game must be finished with %v", calc)
+		}
+		return nil
+	}
+	var ok bool
+	switch o.Verdict() {
+	case chess.VerdictCheckmate, chess.VerdictStalemate:
+		ok = o == calc
+	case chess.VerdictInsufficientMaterial:
+		ok = b.IsInsufficientMaterial()
+	case chess.VerdictDeadPosition:
+		ok = b.IsDeadPosition()
+	case chess.VerdictMoves75:
+		ok = b.Raw().MoveCounter >= 150
+	case chess.VerdictMoves50:
+		ok = b.Raw().MoveCounter >= 100
+	case chess.VerdictRepeat5:
+		ok = calc.Verdict() == chess.VerdictRepeat5
+	case chess.VerdictRepeat3:
+		ok = calc.Verdict() == chess.VerdictRepeat3 || calc.Verdict() == chess.VerdictRepeat5
+	default:
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("%v contradicts the position", o.Verdict())
+	}
+	return nil
+}
+
+type snapshotGob Snapshot
+
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(snapshotGob(s)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	var res snapshotGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&res); err != nil {
+		return err
+	}
+	*s = Snapshot(res)
+	return nil
+}
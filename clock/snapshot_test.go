@@ -0,0 +1,159 @@
+package clock
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+	nowFn := func() time.Time { return now }
+
+	c, err := ControlFromString("2/60+1:30")
+	require.NoError(t, err)
+
+	start, err := chess.BoardFromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	prefix, err := chess.GameFromUCIList(start, "e2e4")
+	require.NoError(t, err)
+	g := NewGame(prefix, maybe.Some(c), GameOptions{
+		Now: nowFn,
+		Lag: maybe.Some(LagOptions{}),
+	})
+	push := func(g *Game, s string, d time.Duration) {
+		now = now.Add(d)
+		mv, err := chess.MoveFromUCI(s, g.CurBoard())
+		require.NoError(t, err)
+		require.NoError(t, g.Push(mv))
+	}
+	push(g, "e7e5", 3*time.Second)
+	push(g, "g1f3", 4*time.Second)
+	push(g, "b8c6", 5*time.Second)
+	now = now.Add(2 * time.Second)
+
+	snap := g.Snapshot()
+	assert.Equal(t, [chess.ColorMax]int{1, 1}, snap.Timer.Stage)
+	assert.Equal(t, [chess.ColorMax]int{0, 0}, snap.Timer.MovesLeft)
+	assert.Len(t, snap.History, 3)
+
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	var fromJSON Snapshot
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, snap, fromJSON)
+
+	data, err = snap.MarshalBinary()
+	require.NoError(t, err)
+	var fromBinary Snapshot
+	require.NoError(t, fromBinary.UnmarshalBinary(data))
+	assert.Equal(t, snap, fromBinary)
+
+	// The server was down for 10 seconds.
+	now = now.Add(10 * time.Second)
+	g2, err := GameFromSnapshot(fromJSON, GameOptions{Now: nowFn})
+	require.NoError(t, err)
+	assert.Equal(t, g.CurBoard().FEN(), g2.CurBoard().FEN())
+	assert.Equal(t, g.ClockHistory(), g2.ClockHistory())
+	clk, ok := g.Clock()
+	require.True(t, ok)
+	clk2, ok := g2.Clock()
+	require.True(t, ok)
+	assert.Equal(t, clk, clk2)
+	assert.Equal(t, Clock{White: 76 * time.Second, Black: 84 * time.Second, WhiteTicking: true}, clk2)
+
+	for _, s := range []string{"f1c4", "g8f6", "d2d3"} {
+		push(g, s, time.Second)
+		now = now.Add(-time.Second)
+		push(g2, s, time.Second)
+		clk, _ := g.Clock()
+		clk2, _ := g2.Clock()
+		assert.Equal(t, clk, clk2)
+	}
+	stats, _ := g.LagStats(chess.ColorWhite)
+	stats2, _ := g2.LagStats(chess.ColorWhite)
+	assert.Equal(t, stats, stats2)
+}
+
+func TestSnapshotPaused(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+	nowFn := func() time.Time { return now }
+
+	c, err := ControlFromString("60")
+	require.NoError(t, err)
+	g := NewGame(chess.NewGame(), maybe.Some(c), GameOptions{Now: nowFn})
+	now = now.Add(5 * time.Second)
+	require.NoError(t, g.PauseTimer())
+	snap := g.Snapshot()
+
+	// The game is adjourned until the next day.
+	now = now.Add(24 * time.Hour)
+	g2, err := GameFromSnapshot(snap, GameOptions{Now: nowFn})
+	require.NoError(t, err)
+	require.NoError(t, g2.ResumeTimer())
+	clk, _ := g2.Clock()
+	assert.Equal(t, Clock{White: 55 * time.Second, Black: 60 * time.Second, WhiteTicking: true}, clk)
+
+	// Broken snapshots are rejected.
+	bad := snap
+	ts := *snap.Timer
+	ts.Stage[chess.ColorWhite] = 1
+	bad.Timer = &ts
+	_, err = GameFromSnapshot(bad, GameOptions{Now: nowFn})
+	assert.EqualError(t, err, "timer: validate: bad stage for white")
+}
+
+func TestSnapshotBad(t *testing.T) {
+	g := NewGame(chess.NewGame(), maybe.None[Control](), GameOptions{})
+	for _, mv := range []string{"f2f3", "e7e5", "g2g4"} {
+		require.NoError(t, g.Inner().PushMoveUCI(mv))
+	}
+	snap := g.Snapshot()
+	_, err := GameFromSnapshot(snap, GameOptions{})
+	require.NoError(t, err)
+
+	bad := snap
+	bad.Verdict = chess.Verdict(200)
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad verdict")
+
+	bad = snap
+	bad.Filter = chess.VerdictFilter(10)
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad filter")
+
+	bad = snap
+	bad.Verdict = chess.VerdictCheckmate
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad outcome: checkmate contradicts the position")
+
+	bad = snap
+	bad.Verdict = chess.VerdictInsufficientMaterial
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad outcome: insufficient material contradicts the position")
+
+	// The game is over after the mate, so it cannot be running or finished for another reason.
+	bad = snap
+	bad.Moves = append(bad.Moves, "d8h4")
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad outcome: game must be finished with black checkmates")
+	bad.Verdict = chess.VerdictResign
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	assert.EqualError(t, err, "bad outcome: game must be finished with black checkmates")
+	bad.Verdict = chess.VerdictCheckmate
+	bad.Winner = chess.ColorBlack
+	_, err = GameFromSnapshot(bad, GameOptions{})
+	require.NoError(t, err)
+
+	ts := TimerSnapshot{Control: Control{White: ControlSide{{Time: time.Minute}}, Black: ControlSide{{Time: time.Minute}}}}
+	require.NoError(t, ts.Validate())
+	ts.Verdict = chess.Verdict(200)
+	assert.EqualError(t, ts.Validate(), "bad verdict")
+}
@@ -10,6 +10,9 @@ import (
 type subController struct {
 	control ControlSide
 	left    int
+
+	// Number of control items already passed.
+	stage int
 }
 
 func (c *subController) init(control ControlSide, d *time.Duration) {
@@ -18,13 +21,44 @@ func (c *subController) init(control ControlSide, d *time.Duration) {
 	*d = control[0].Time
 }
 
-func (c *subController) flip(d *time.Duration) {
+// Returns how much the clock goes down when the time used on the current move grows from the
+// given value by elapsed.
+func (c *subController) charge(used, elapsed time.Duration) time.Duration {
+	item := &c.control[0]
+	if item.Delay == 0 || item.DelayKind != DelaySimple {
+		return elapsed
+	}
+	return max(0, used+elapsed-item.Delay) - max(0, used-item.Delay)
+}
+
+// Returns the time which can be used on the current move before the clock starts going down.
+func (c *subController) grace(used time.Duration) time.Duration {
+	item := &c.control[0]
+	if item.DelayKind != DelaySimple {
+		return 0
+	}
+	return max(0, item.Delay-used)
+}
+
+func (c *subController) mode() ControlMode {
+	return c.control[0].Mode
+}
+
+func (c *subController) flip(d *time.Duration, used time.Duration) {
+	if c.mode() == ModePerMove {
+		*d = c.control[0].Time
+		return
+	}
+	if item := &c.control[0]; item.DelayKind == DelayBronstein {
+		*d += min(used, item.Delay)
+	}
 	*d += c.control[0].Inc
 	if c.control[0].Moves != 0 {
 		c.left--
 		if c.left == 0 {
 			if len(c.control) > 1 {
 				c.control = c.control[1:]
+				c.stage++
 			}
 			c.left = c.control[0].Moves
 			*d += c.control[0].Time
@@ -33,25 +67,32 @@ func (c *subController) flip(d *time.Duration) {
 }
 
 type controller struct {
-	sub [chess.ColorMax]subController
+	control Control
+	sub     [chess.ColorMax]subController
 }
 
 func (c *controller) init(control Control, clock *SimpleClock) {
+	c.control = control.Clone()
 	for color := range chess.ColorMax {
 		c.sub[color].init(*control.Side(color), clock.Side(color))
 	}
 }
 
-func (c *controller) flip(who chess.Color, clock *SimpleClock) {
-	c.sub[who].flip(clock.Side(who))
+func (c *controller) flip(who chess.Color, clock *SimpleClock, used time.Duration) {
+	c.sub[who].flip(clock.Side(who), used)
 }
 
+// UCI doesn't support delays, so they are reported as increments. This is exact for the simple
+// delay if the engine always spends more time than the delay, and an upper bound for the Bronstein
+// delay.
 func (c *controller) uciTimeSpec(who chess.Color, clock SimpleClock) UCITimeSpec {
+	white := &c.sub[chess.ColorWhite].control[0]
+	black := &c.sub[chess.ColorBlack].control[0]
 	return UCITimeSpec{
 		Wtime:     clock.White,
 		Btime:     clock.Black,
-		Winc:      c.sub[chess.ColorWhite].control[0].Inc,
-		Binc:      c.sub[chess.ColorBlack].control[0].Inc,
+		Winc:      white.Inc + white.Delay,
+		Binc:      black.Inc + black.Delay,
 		MovesToGo: c.sub[who].left,
 	}
 }
@@ -63,6 +104,14 @@ type Timer struct {
 	nowFn   func() time.Time
 	ctrl    controller
 	clock   SimpleClock
+
+	// Time used on the current move before cur.
+	used time.Duration
+
+	paused bool
+
+	// Nil if lag compensation is disabled.
+	lag *lagTracker
 }
 
 type TimerOptions struct {
@@ -70,6 +119,7 @@ type TimerOptions struct {
 	Outcome  chess.Outcome
 	Now      func() time.Time
 	Clock    maybe.Maybe[SimpleClock]
+	Lag      maybe.Maybe[LagOptions]
 }
 
 func NewTimer(side chess.Color, control Control, o TimerOptions) *Timer {
@@ -83,6 +133,9 @@ func NewTimer(side chess.Color, control Control, o TimerOptions) *Timer {
 		cur:     nowFn(),
 		nowFn:   nowFn,
 	}
+	if lo, ok := o.Lag.TryGet(); ok {
+		t.lag = newLagTracker(lo)
+	}
 	t.ctrl.init(control, &t.clock)
 	t.doCheckForfeit()
 	for range o.NumFlips {
@@ -112,58 +165,115 @@ func (t *Timer) Clock() Clock {
 		WhiteTicking: false,
 		BlackTicking: false,
 	}
-	if t.outcome.IsFinished() {
+	if t.outcome.IsFinished() || t.paused {
 		return c
 	}
 	*c.SideTicking(t.side) = true
 	now := t.nowFn()
 	if now.After(t.cur) {
-		*c.Side(t.side) -= now.Sub(t.cur)
+		charged := t.ctrl.sub[t.side].charge(t.used, now.Sub(t.cur))
+		*c.Side(t.side) -= charged
+		if t.ctrl.sub[t.side].mode() == ModeHourglass {
+			*c.Side(t.side.Inv()) += charged
+		}
 	}
 	return c
 }
 
 func (t *Timer) Deadline() (time.Time, bool) {
-	if t.outcome.IsFinished() {
+	if t.outcome.IsFinished() || t.paused {
+		return time.Time{}, false
+	}
+	return t.cur.Add(t.ctrl.sub[t.side].grace(t.used) + *t.clock.Side(t.side) + t.lagAllowance(t.side)), true
+}
+
+// ClockShowsAt returns the moment when the clock of the side to move, as returned by Clock, goes
+// down to d. Unlike Deadline, lag allowance is not taken into account, as it doesn't affect the
+// shown clock. The delay is still taken into account, as the clock doesn't go down during it.
+func (t *Timer) ClockShowsAt(d time.Duration) (time.Time, bool) {
+	if t.outcome.IsFinished() || t.paused {
 		return time.Time{}, false
 	}
-	return t.cur.Add(*t.clock.Side(t.side)), true
+	return t.cur.Add(t.ctrl.sub[t.side].grace(t.used) + *t.clock.Side(t.side) - d), true
+}
+
+// With lag compensation, the flag falls only after the clock goes below the compensation which
+// the side can get on its move. So, the clock may show negative time for a short while.
+func (t *Timer) lagAllowance(c chess.Color) time.Duration {
+	if t.lag == nil {
+		return 0
+	}
+	return t.lag.allowance(c)
+}
+
+func (t *Timer) isFlagged(c chess.Color) bool {
+	return *t.clock.Side(c) <= -t.lagAllowance(c)
 }
 
 func (t *Timer) doCheckForfeit() {
-	if !t.outcome.IsFinished() && *t.clock.Side(t.side) <= 0 {
+	if !t.outcome.IsFinished() && t.isFlagged(t.side) {
 		t.outcome = chess.MustWinOutcome(chess.VerdictTimeForfeit, t.side.Inv())
 	}
 }
 
 func (t *Timer) Update() {
-	if t.outcome.IsFinished() {
+	t.doUpdate()
+	t.doCheckForfeit()
+}
+
+func (t *Timer) doUpdate() {
+	if t.outcome.IsFinished() || t.paused {
 		return
 	}
 	now := t.nowFn()
 	if now.After(t.cur) {
-		*t.clock.Side(t.side) -= now.Sub(t.cur)
+		elapsed := now.Sub(t.cur)
+		charged := t.ctrl.sub[t.side].charge(t.used, elapsed)
+		*t.clock.Side(t.side) -= charged
+		if t.ctrl.sub[t.side].mode() == ModeHourglass {
+			*t.clock.Side(t.side.Inv()) += charged
+		}
+		t.used += elapsed
 		t.cur = now
 	}
-	t.doCheckForfeit()
 }
 
 func (t *Timer) doFlip() {
-	t.ctrl.flip(t.side, &t.clock)
+	t.ctrl.flip(t.side, &t.clock, t.used)
+	t.used = 0
 	t.side = t.side.Inv()
 }
 
 func (t *Timer) Flip() {
+	t.FlipWithLag(LagReport{})
+}
+
+// FlipWithLag is like Flip, but also compensates the network lag according to the report. If lag
+// compensation is disabled, the report is ignored.
+func (t *Timer) FlipWithLag(r LagReport) {
 	if t.outcome.IsFinished() {
 		return
 	}
-	t.Update()
+	t.doUpdate()
+	if t.lag != nil {
+		*t.clock.Side(t.side) += t.lag.compensate(t.side, t.used, r)
+	}
+	t.doCheckForfeit()
 	if t.outcome.IsFinished() {
 		return
 	}
 	t.doFlip()
 }
 
+// LagStats returns lag compensation statistics for the given side. It returns false if lag
+// compensation is disabled.
+func (t *Timer) LagStats(c chess.Color) (LagStats, bool) {
+	if t.lag == nil {
+		return LagStats{}, false
+	}
+	return t.lag.stats[c], true
+}
+
 func (t *Timer) Stop(outcome chess.Outcome) {
 	if !outcome.IsFinished() || t.outcome.IsFinished() {
 		return
@@ -175,6 +285,70 @@ func (t *Timer) Stop(outcome chess.Outcome) {
 	t.outcome = outcome
 }
 
+func (t *Timer) Paused() bool { return t.paused }
+
+// MoveTime returns the time spent on the current move so far.
+func (t *Timer) MoveTime() time.Duration {
+	used := t.used
+	if t.outcome.IsFinished() || t.paused {
+		return used
+	}
+	if now := t.nowFn(); now.After(t.cur) {
+		used += now.Sub(t.cur)
+	}
+	return used
+}
+
+// Pause stops the clock of the side to move until Resume is called. Moves can still be made while
+// the timer is paused.
+func (t *Timer) Pause() {
+	if t.outcome.IsFinished() || t.paused {
+		return
+	}
+	t.Update()
+	if t.outcome.IsFinished() {
+		return
+	}
+	t.paused = true
+}
+
+func (t *Timer) Resume() {
+	if !t.paused {
+		return
+	}
+	t.paused = false
+	t.cur = t.nowFn()
+}
+
+// Adjust adds d to the clock of the given side. If d is negative, the time is subtracted, and the
+// side may forfeit on time as a result, in the same way as if its clock ran out.
+func (t *Timer) Adjust(c chess.Color, d time.Duration) {
+	if t.outcome.IsFinished() {
+		return
+	}
+	t.Update()
+	if t.outcome.IsFinished() {
+		return
+	}
+	*t.clock.Side(c) += d
+	if t.isFlagged(c) {
+		t.outcome = chess.MustWinOutcome(chess.VerdictTimeForfeit, c.Inv())
+	}
+}
+
 func (t *Timer) UCITimeSpec() UCITimeSpec {
 	return t.ctrl.uciTimeSpec(t.side, t.clock)
 }
+
+func (t *Timer) Mode(c chess.Color) ControlMode {
+	return t.ctrl.sub[c].mode()
+}
+
+// Movetime returns the time left for the current move if the side to move has fixed time per move.
+// Such controls are better mapped into "go movetime" than into UCITimeSpec.
+func (t *Timer) Movetime() (time.Duration, bool) {
+	if t.ctrl.sub[t.side].mode() != ModePerMove {
+		return 0, false
+	}
+	return *t.clock.Side(t.side), true
+}
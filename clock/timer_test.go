@@ -93,6 +93,254 @@ func TestTimerSimple(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestSimpleDelay(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("10d3")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+	})
+
+	dl, ok := timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(13*time.Second), dl)
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, Clock{White: 10 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Update()
+	dl, ok = timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(11*time.Second), dl)
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, Clock{White: 9 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Flip()
+	assert.Equal(t, Clock{White: 9 * time.Second, Black: 10 * time.Second, BlackTicking: true}, timer.Clock())
+	assert.Equal(t, UCITimeSpec{
+		Wtime: 9 * time.Second,
+		Btime: 10 * time.Second,
+		Winc:  3 * time.Second,
+		Binc:  3 * time.Second,
+	}, timer.UCITimeSpec())
+
+	now = now.Add(1 * time.Second)
+	timer.Flip()
+	assert.Equal(t, Clock{White: 9 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	now = now.Add(12 * time.Second)
+	timer.Update()
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), timer.Outcome())
+}
+
+func TestBronsteinDelay(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("10b3")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+	})
+
+	dl, ok := timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(10*time.Second), dl)
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, Clock{White: 8 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Update()
+	now = now.Add(2 * time.Second)
+	timer.Flip()
+	assert.Equal(t, Clock{White: 9 * time.Second, Black: 10 * time.Second, BlackTicking: true}, timer.Clock())
+
+	now = now.Add(1 * time.Second)
+	timer.Flip()
+	assert.Equal(t, Clock{White: 9 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	now = now.Add(9 * time.Second)
+	timer.Update()
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), timer.Outcome())
+}
+
+func TestHourglass(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("h10")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+	})
+
+	now = now.Add(3 * time.Second)
+	assert.Equal(t, Clock{White: 7 * time.Second, Black: 13 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Flip()
+	assert.Equal(t, Clock{White: 7 * time.Second, Black: 13 * time.Second, BlackTicking: true}, timer.Clock())
+	assert.Equal(t, UCITimeSpec{Wtime: 7 * time.Second, Btime: 13 * time.Second}, timer.UCITimeSpec())
+	_, ok := timer.Movetime()
+	assert.False(t, ok)
+
+	now = now.Add(5 * time.Second)
+	timer.Flip()
+	assert.Equal(t, Clock{White: 12 * time.Second, Black: 8 * time.Second, WhiteTicking: true}, timer.Clock())
+	dl, ok := timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(12*time.Second), dl)
+
+	now = now.Add(12 * time.Second)
+	timer.Update()
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), timer.Outcome())
+	assert.Equal(t, Clock{White: 0, Black: 20 * time.Second}, timer.Clock())
+}
+
+func TestPerMove(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("s5")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+	})
+
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, Clock{White: 3 * time.Second, Black: 5 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Flip()
+	assert.Equal(t, Clock{White: 5 * time.Second, Black: 5 * time.Second, BlackTicking: true}, timer.Clock())
+	mt, ok := timer.Movetime()
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, mt)
+
+	now = now.Add(4 * time.Second)
+	timer.Update()
+	mt, ok = timer.Movetime()
+	assert.True(t, ok)
+	assert.Equal(t, 1*time.Second, mt)
+	timer.Flip()
+	assert.Equal(t, Clock{White: 5 * time.Second, Black: 5 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	now = now.Add(5 * time.Second)
+	timer.Update()
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), timer.Outcome())
+}
+
+func TestPauseAndAdjust(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("60")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+	})
+
+	now = now.Add(10 * time.Second)
+	timer.Pause()
+	assert.True(t, timer.Paused())
+	now = now.Add(100 * time.Second)
+	timer.Update()
+	assert.Equal(t, Clock{White: 50 * time.Second, Black: 60 * time.Second}, timer.Clock())
+	_, ok := timer.Deadline()
+	assert.False(t, ok)
+	assert.False(t, timer.Outcome().IsFinished())
+
+	timer.Resume()
+	assert.False(t, timer.Paused())
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, Clock{White: 45 * time.Second, Black: 60 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	timer.Adjust(chess.ColorWhite, 15*time.Second)
+	assert.Equal(t, Clock{White: 60 * time.Second, Black: 60 * time.Second, WhiteTicking: true}, timer.Clock())
+	timer.Adjust(chess.ColorBlack, -60*time.Second)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite), timer.Outcome())
+}
+
+func TestLagCompensation(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("10")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+		Lag: maybe.Some(LagOptions{}),
+	})
+
+	now = now.Add(3 * time.Second)
+	timer.FlipWithLag(LagReport{
+		ThinkTime: maybe.Some(2500 * time.Millisecond),
+		RTT:       maybe.Some(400 * time.Millisecond),
+	})
+	assert.Equal(t, Clock{White: 7400 * time.Millisecond, Black: 10 * time.Second, BlackTicking: true}, timer.Clock())
+
+	// Reported think time cannot be trusted without the round-trip time.
+	now = now.Add(2 * time.Second)
+	timer.FlipWithLag(LagReport{ThinkTime: maybe.Some[time.Duration](0)})
+	assert.Equal(t, Clock{White: 7400 * time.Millisecond, Black: 8 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	// The flag doesn't fall while the move may still be in transit.
+	now = now.Add(7900 * time.Millisecond)
+	timer.Update()
+	assert.False(t, timer.Outcome().IsFinished())
+	timer.FlipWithLag(LagReport{RTT: maybe.Some(600 * time.Millisecond)})
+	assert.Equal(t, Clock{White: 100 * time.Millisecond, Black: 8 * time.Second, BlackTicking: true}, timer.Clock())
+
+	stats, ok := timer.LagStats(chess.ColorWhite)
+	assert.True(t, ok)
+	assert.Equal(t, LagStats{
+		Moves:       2,
+		TotalLag:    1 * time.Second,
+		MaxLag:      600 * time.Millisecond,
+		Compensated: 1 * time.Second,
+		Quota:       200 * time.Millisecond,
+	}, stats)
+	assert.Equal(t, 500*time.Millisecond, stats.AvgLag())
+
+	// Adjustment flags in the same way as the running clock, i.e. with lag allowance.
+	timer.Adjust(chess.ColorWhite, -300*time.Millisecond)
+	assert.False(t, timer.Outcome().IsFinished())
+
+	dl, ok := timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(9*time.Second), dl)
+	now = now.Add(9100 * time.Millisecond)
+	timer.Update()
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorWhite), timer.Outcome())
+}
+
+func TestClockShowsAt(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+
+	c, err := ControlFromString("10d3")
+	require.NoError(t, err)
+
+	timer := NewTimer(chess.ColorWhite, c, TimerOptions{
+		Now: func() time.Time { return now },
+		Lag: maybe.Some(LagOptions{}),
+	})
+
+	// The delay is counted, but the lag allowance is not.
+	at, ok := timer.ClockShowsAt(2 * time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(11*time.Second), at)
+	dl, ok := timer.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(14*time.Second), dl)
+
+	now = at
+	assert.Equal(t, Clock{White: 2 * time.Second, Black: 10 * time.Second, WhiteTicking: true}, timer.Clock())
+
+	timer.Pause()
+	_, ok = timer.ClockShowsAt(time.Second)
+	assert.False(t, ok)
+}
+
 func TestMultiControl(t *testing.T) {
 	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
 	require.NoError(t, err)
@@ -0,0 +1,111 @@
+// An example on how to generate training data from engine self-play games.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/play"
+	"github.com/alex65536/go-chess/selfplay"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// Loads the opening suite. Each line contains a FEN, optionally followed by "moves" and the moves
+// in UCI format.
+func loadOpenings(path string) ([]*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+	var res []*chess.Game
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fen, moves, _ := strings.Cut(line, " moves ")
+		b, err := chess.BoardFromFEN(strings.TrimSpace(fen))
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %w", line, err)
+		}
+		g, err := chess.GameFromUCIList(b, moves)
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %w", line, err)
+		}
+		res = append(res, g)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	return res, nil
+}
+
+func main() {
+	engine := flag.String("engine", "stockfish", "engine executable")
+	openings := flag.String("openings", "", "file with opening positions")
+	games := flag.Int("games", 10, "number of games")
+	nodes := flag.Int64("nodes", 5000, "nodes per move")
+	out := flag.String("out", "data.txt", "output file")
+	binary := flag.Bool("binary", false, "use compact binary output format")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	opts := selfplay.Options{
+		// Search each position with fixed number of nodes, so the results do not depend on the
+		// machine load.
+		GoOptions: uci.GoOptions{Nodes: maybe.Some(*nodes)},
+		Games:     *games,
+		// Finish hopeless and very long games early.
+		Adjudication: maybe.Some(play.AdjudicationRules{
+			Resign:   maybe.Some(play.ResignRule{MoveCount: 4, Score: 1000}),
+			MaxMoves: maybe.Some(200),
+		}),
+		OnGame: func(s selfplay.Stats) {
+			fmt.Fprintf(os.Stderr, "games: %v, samples: %v\n", s.Games, s.Samples)
+		},
+	}
+	if *openings != "" {
+		var err error
+		opts.Openings, err = loadOpenings(*openings)
+		if err != nil {
+			panic(fmt.Errorf("load openings: %w", err))
+		}
+	}
+
+	e, err := uci.NewEasyEngine(ctx, uci.EasyEngineOptions{
+		Name:            *engine,
+		WaitInitialized: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer e.Close()
+
+	f, err := os.Create(*out)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	var w selfplay.Writer = selfplay.NewTextWriter(f)
+	if *binary {
+		w = selfplay.NewBinaryWriter(f)
+	}
+
+	st, err := selfplay.Generate(ctx, e, opts, w)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf(
+		"%v games (+%v =%v -%v), %v failed, %v of %v positions written\n",
+		st.Games, st.WhiteWins, st.Draws, st.BlackWins, st.Failed, st.Samples, st.Positions,
+	)
+}
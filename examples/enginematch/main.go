@@ -213,4 +213,17 @@ func main() {
 		panic(err)
 	}
 	fmt.Println(styledMoves)
+
+	// Print the timing statistics for each engine. They help to choose the margin for the search
+	// deadline: if the engines lose on time or often approach the deadline, the margin must be
+	// increased.
+	fmt.Println()
+	for c := range chess.ColorMax {
+		st := engines[c].TimingStats()
+		fmt.Printf(
+			"%v: searches = %v, stop latency = %v (max %v), slack = %v (min %v), near deadline = %v, losses = %v\n",
+			c.LongString(), st.Searches, st.StopLatency.Avg(), st.StopLatency.Max,
+			st.Slack.Avg(), st.Slack.Min, st.NearDeadline, st.TimeLosses,
+		)
+	}
 }
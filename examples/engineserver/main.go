@@ -0,0 +1,53 @@
+// An example on how to expose a local chess engine over the network.
+//
+// Clients connect to it using remote.Dial() and then use the obtained process as usual, e.g. by
+// passing it to uci.NewEngine().
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/remote"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprintf(os.Stderr, "usage: %v ADDR ENGINE [ARGS...]\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	// Stop the server on Ctrl+C. All the running engines are killed then.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// Take the token from the environment, so it doesn't appear in the process list.
+	token := os.Getenv("ENGINE_TOKEN")
+	if token == "" {
+		fmt.Fprintf(os.Stderr, "ENGINE_TOKEN must be set\n")
+		os.Exit(2)
+	}
+
+	l, err := net.Listen("tcp", os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+
+	// Each accepted connection runs its own engine process.
+	srv, err := remote.NewServer(remote.ServerOptions{
+		Token:  token,
+		Name:   os.Args[2],
+		Args:   os.Args[3:],
+		Logger: uci.NewStderrLogger(),
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := srv.Serve(ctx, l); err != nil {
+		panic(err)
+	}
+}
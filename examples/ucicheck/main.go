@@ -0,0 +1,31 @@
+// An example on how to check whether a chess engine follows the UCI protocol.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alex65536/go-chess/uci/conformance"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "usage: %v ENGINE [ARGS...]\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	// Run all the default scenarios. Each scenario starts a fresh engine process, so the scenarios
+	// don't affect each other.
+	report := conformance.Run(
+		context.Background(),
+		conformance.CmdFactory(os.Args[1], os.Args[2:]...),
+		conformance.Options{},
+	)
+
+	// Print the report. It lists all the scenarios together with the violations found.
+	fmt.Print(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,153 @@
+package play
+
+import (
+	"fmt"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// ResignRule adjudicates the game as lost for the side which reported score of -Score or lower
+// for MoveCount consecutive moves, while its opponent reported score of +Score or higher for the
+// same number of its own moves.
+type ResignRule struct {
+	MoveCount int
+	Score     int32 // Centipawns.
+}
+
+// DrawRule adjudicates the game as draw if the game is past full move MoveNumber, and both sides
+// reported absolute score not greater than Score for MoveCount consecutive moves each.
+//
+// Move numbers here and in AdjudicationRules are the ones of the current position. So, if the game
+// starts from a position with move number other than 1, they include the moves made before.
+type DrawRule struct {
+	MoveNumber int
+	MoveCount  int
+	Score      int32 // Centipawns.
+}
+
+type AdjudicationRules struct {
+	Resign maybe.Maybe[ResignRule]
+	Draw   maybe.Maybe[DrawRule]
+
+	// Adjudicate the game as draw once the game is past full move MaxMoves.
+	MaxMoves maybe.Maybe[int]
+}
+
+func (r AdjudicationRules) Clone() AdjudicationRules {
+	return r
+}
+
+func (r *AdjudicationRules) Validate() error {
+	if rr, ok := r.Resign.TryGet(); ok {
+		if rr.MoveCount <= 0 {
+			return fmt.Errorf("non-positive resign move count")
+		}
+		if rr.Score <= 0 {
+			return fmt.Errorf("non-positive resign score")
+		}
+	}
+	if dr, ok := r.Draw.TryGet(); ok {
+		if dr.MoveCount <= 0 {
+			return fmt.Errorf("non-positive draw move count")
+		}
+		if dr.Score < 0 {
+			return fmt.Errorf("negative draw score")
+		}
+	}
+	if m, ok := r.MaxMoves.TryGet(); ok && m <= 0 {
+		return fmt.Errorf("non-positive max moves")
+	}
+	return nil
+}
+
+type Decision struct {
+	// Running outcome means that the game must continue.
+	Outcome chess.Outcome
+	Reason  string
+}
+
+func (d Decision) IsFinished() bool {
+	return d.Outcome.IsFinished()
+}
+
+// Adjudicator tracks the scores reported by the engines during one game and decides whether the
+// game can be finished early.
+type Adjudicator struct {
+	r AdjudicationRules
+
+	losing  [chess.ColorMax]int
+	winning [chess.ColorMax]int
+	drawish int
+}
+
+func NewAdjudicator(r AdjudicationRules) (*Adjudicator, error) {
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return &Adjudicator{r: r.Clone()}, nil
+}
+
+// Update must be called after each move made by side. The score is the one reported by side for
+// its move, from its own point of view. Moves without score reset the counters.
+func (a *Adjudicator) Update(g *clock.Game, side chess.Color, score maybe.Maybe[uci.Score]) Decision {
+	if g.IsFinished() {
+		return Decision{Outcome: g.Outcome()}
+	}
+
+	if sc, ok := score.TryGet(); ok {
+		cp, _ := sc.Centipawns()
+		if rr, ok := a.r.Resign.TryGet(); ok {
+			a.losing[side] = incIf(a.losing[side], cp <= -rr.Score)
+			a.winning[side] = incIf(a.winning[side], cp >= rr.Score)
+		}
+		if dr, ok := a.r.Draw.TryGet(); ok {
+			a.drawish = incIf(a.drawish, -dr.Score <= cp && cp <= dr.Score)
+		}
+	} else {
+		a.losing[side] = 0
+		a.winning[side] = 0
+		a.drawish = 0
+	}
+
+	if rr, ok := a.r.Resign.TryGet(); ok {
+		for c := range chess.ColorMax {
+			if a.losing[c] >= rr.MoveCount && a.winning[c.Inv()] >= rr.MoveCount {
+				return Decision{
+					Outcome: chess.MustWinOutcome(chess.VerdictWinAdjudication, c.Inv()),
+					Reason: fmt.Sprintf(
+						"%v has score %v or lower for %v moves",
+						c.LongString(), uci.ScoreCentipawns(-rr.Score), rr.MoveCount,
+					),
+				}
+			}
+		}
+	}
+	if dr, ok := a.r.Draw.TryGet(); ok {
+		if int(g.CurBoard().MoveNumber()) > dr.MoveNumber && a.drawish >= 2*dr.MoveCount {
+			return Decision{
+				Outcome: chess.MustDrawOutcome(chess.VerdictDrawAdjudication),
+				Reason: fmt.Sprintf(
+					"both sides have score within %v for %v moves",
+					uci.ScoreCentipawns(dr.Score), dr.MoveCount,
+				),
+			}
+		}
+	}
+	if m, ok := a.r.MaxMoves.TryGet(); ok && int(g.CurBoard().MoveNumber()) > m {
+		return Decision{
+			Outcome: chess.MustDrawOutcome(chess.VerdictDrawAdjudication),
+			Reason:  fmt.Sprintf("game length exceeds %v moves", m),
+		}
+	}
+	return Decision{Outcome: chess.RunningOutcome()}
+}
+
+func incIf(v int, cond bool) int {
+	if cond {
+		return v + 1
+	}
+	return 0
+}
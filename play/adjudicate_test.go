@@ -0,0 +1,99 @@
+package play
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjudicateResign(t *testing.T) {
+	a, err := NewAdjudicator(AdjudicationRules{
+		Resign: maybe.Some(ResignRule{MoveCount: 2, Score: 500}),
+	})
+	require.NoError(t, err)
+
+	game := newGame(maybe.None[clock.Control]())
+	cp := func(v int32) maybe.Maybe[uci.Score] { return maybe.Some(uci.ScoreCentipawns(v)) }
+	steps := []struct {
+		score maybe.Maybe[uci.Score]
+		done  bool
+	}{
+		{score: cp(600)},
+		{score: cp(-600)},
+		{score: maybe.None[uci.Score]()},
+		{score: cp(-700)},
+		{score: cp(550)},
+		{score: maybe.Some(uci.ScoreMate(-5))},
+		{score: cp(800), done: true},
+	}
+	moves := []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1c4", "g8f6", "d2d3"}
+	for i, st := range steps {
+		side := game.CurSide()
+		require.NoError(t, game.Inner().PushMoveUCI(moves[i]))
+		d := a.Update(game, side, st.score)
+		if !st.done {
+			assert.False(t, d.IsFinished(), "step %v", i)
+			continue
+		}
+		assert.Equal(t, chess.MustWinOutcome(chess.VerdictWinAdjudication, chess.ColorWhite), d.Outcome)
+		assert.NotEmpty(t, d.Reason)
+	}
+}
+
+func TestAdjudicateDraw(t *testing.T) {
+	a, err := NewAdjudicator(AdjudicationRules{
+		Draw: maybe.Some(DrawRule{MoveNumber: 3, MoveCount: 2, Score: 10}),
+	})
+	require.NoError(t, err)
+
+	game := newGame(maybe.None[clock.Control]())
+	moves := []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1c4", "g8f6"}
+	for i, mv := range moves {
+		side := game.CurSide()
+		require.NoError(t, game.Inner().PushMoveUCI(mv))
+		d := a.Update(game, side, maybe.Some(uci.ScoreCentipawns(5)))
+		if i+1 < len(moves) {
+			assert.False(t, d.IsFinished(), "move %v", i)
+		} else {
+			assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAdjudication), d.Outcome)
+		}
+	}
+
+	_, err = NewAdjudicator(AdjudicationRules{Draw: maybe.Some(DrawRule{})})
+	assert.Error(t, err)
+}
+
+func TestAdjudicateMaxMoves(t *testing.T) {
+	game := newGame(maybe.None[clock.Control]())
+	res, err := PlayGame(context.Background(), game, [chess.ColorMax]Player{
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+		NewRandomPlayer(rand.New(rand.NewPCG(3, 4))),
+	}, Options{
+		Adjudication: maybe.Some(AdjudicationRules{MaxMoves: maybe.Some(5)}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAdjudication), res.Outcome)
+	assert.NotEmpty(t, res.Adjudication)
+	assert.Equal(t, 10, game.Inner().Len())
+
+	// Move numbers are taken from the position, not counted from the start of the game.
+	b, err := chess.BoardFromFEN("r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 5")
+	require.NoError(t, err)
+	game = clock.NewGame(chess.NewGameWithPosition(b), maybe.None[clock.Control](), clock.GameOptions{})
+	res, err = PlayGame(context.Background(), game, [chess.ColorMax]Player{
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+		NewRandomPlayer(rand.New(rand.NewPCG(3, 4))),
+	}, Options{
+		Adjudication: maybe.Some(AdjudicationRules{MaxMoves: maybe.Some(5)}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAdjudication), res.Outcome)
+	assert.Equal(t, 2, game.Inner().Len())
+}
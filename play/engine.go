@@ -0,0 +1,179 @@
+package play
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+type EnginePlayerOptions struct {
+	// Think on the opponent's time. The engine must support pondering, and "Ponder" option must
+	// be already set.
+	Ponder bool
+
+	// Base options for "go". TimeSpec is filled from the game clock, if any.
+	GoOptions uci.GoOptions
+
+	// Receives the search updates.
+	Consumer uci.InfoConsumer
+}
+
+func (o EnginePlayerOptions) Clone() EnginePlayerOptions {
+	o.GoOptions = o.GoOptions.Clone()
+	return o
+}
+
+type EnginePlayer struct {
+	e *uci.Engine
+	o EnginePlayerOptions
+
+	last *uci.Search
+
+	// Ponder move returned by the last search, to be used after our move is added to the game.
+	ponderMove maybe.Maybe[chess.Move]
+	ponderLen  int
+
+	// Current pondering search and the game on which it runs.
+	ponder     *uci.Search
+	ponderGame *chess.Game
+}
+
+var (
+	_ Player   = (*EnginePlayer)(nil)
+	_ Observer = (*EnginePlayer)(nil)
+)
+
+func NewEnginePlayer(e *uci.Engine, o EnginePlayerOptions) *EnginePlayer {
+	return &EnginePlayer{e: e, o: o.Clone()}
+}
+
+func (p *EnginePlayer) Engine() *uci.Engine { return p.e }
+
+// LastSearch returns the last search which produced a move. It can be used to obtain the score
+// or the principal variation.
+func (p *EnginePlayer) LastSearch() *uci.Search { return p.last }
+
+func (p *EnginePlayer) goOptions(g *clock.Game) uci.GoOptions {
+	opts := p.o.GoOptions.Clone()
+	if mt, ok := g.UCIMovetime(); ok {
+		opts.Movetime = maybe.Some(mt)
+	} else if ts, ok := g.UCITimeSpec(); ok {
+		opts.TimeSpec = maybe.Some(ts)
+	}
+	return opts
+}
+
+func (p *EnginePlayer) stopPonder(ctx context.Context) {
+	if p.ponder == nil {
+		return
+	}
+	// Stopping the search during ponder is reported as search error, so ignore it.
+	_ = p.ponder.Stop(ctx, true)
+	p.ponder = nil
+	p.ponderGame = nil
+}
+
+func (p *EnginePlayer) isPonderHit(g *chess.Game) bool {
+	if p.ponder == nil || g.Len() != p.ponderGame.Len() || g.Len() < 2 {
+		return false
+	}
+	n := g.Len()
+	return g.MoveAt(n-1) == p.ponderGame.MoveAt(n-1) && g.MoveAt(n-2) == p.ponderGame.MoveAt(n-2)
+}
+
+func (p *EnginePlayer) Play(ctx context.Context, req Request) (Response, error) {
+	p.ponderMove = maybe.None[chess.Move]()
+
+	var search *uci.Search
+	if p.isPonderHit(req.Game.Inner()) {
+		if err := p.ponder.PonderHit(ctx); err != nil {
+			return Response{}, fmt.Errorf("ponderhit: %w", err)
+		}
+		search = p.ponder
+		p.ponder = nil
+		p.ponderGame = nil
+	} else {
+		p.stopPonder(ctx)
+		if err := p.e.SetPosition(ctx, req.Game.Inner()); err != nil {
+			return Response{}, fmt.Errorf("set position: %w", err)
+		}
+		var err error
+		search, err = p.e.Go(ctx, p.goOptions(req.Game), p.o.Consumer)
+		if err != nil {
+			return Response{}, fmt.Errorf("go: %w", err)
+		}
+	}
+
+	if err := search.Wait(ctx); err != nil {
+		// The search is still running, e.g. because the deadline passed. Stop it, otherwise the
+		// engine rejects the next "position" and "go".
+		_ = search.Stop(context.WithoutCancel(ctx), true)
+		return Response{}, fmt.Errorf("wait: %w", err)
+	}
+	best, err := search.BestMove()
+	if err != nil {
+		return Response{}, fmt.Errorf("best move: %w", err)
+	}
+	p.last = search
+
+	if p.o.Ponder {
+		if pm, ok, err := search.PonderMove(); err == nil && ok {
+			p.ponderMove = maybe.Some(pm)
+			p.ponderLen = req.Game.Inner().Len() + 1
+		}
+	}
+	return Response{Move: best, Score: search.Status().Score}, nil
+}
+
+// OnMove starts pondering after our move is added to the game.
+func (p *EnginePlayer) OnMove(ctx context.Context, g *clock.Game) error {
+	pm, ok := p.ponderMove.TryGet()
+	if !ok || g.IsFinished() || g.Inner().Len() != p.ponderLen {
+		return nil
+	}
+	p.ponderMove = maybe.None[chess.Move]()
+	for c := range chess.ColorMax {
+		if m, ok := g.ControlMode(c); ok && m == clock.ModePerMove {
+			// The search limits are taken from the clock of the side to move, i.e. the opponent.
+			// This works for wtime/btime, but the movetime would be the opponent's one, and the
+			// engine would treat our fixed time per move as sudden death. So, don't ponder then.
+			return nil
+		}
+	}
+
+	game := g.Inner().Clone()
+	if err := game.PushMove(pm); err != nil {
+		// The engine returned a ponder move which is illegal. Just don't ponder.
+		return nil
+	}
+	if game.CalcOutcome().IsFinished() {
+		return nil
+	}
+	if err := p.e.SetPosition(ctx, game); err != nil {
+		return fmt.Errorf("set position: %w", err)
+	}
+	opts := p.goOptions(g)
+	opts.Ponder = true
+	search, err := p.e.Go(ctx, opts, p.o.Consumer)
+	if err != nil {
+		return fmt.Errorf("go ponder: %w", err)
+	}
+	p.ponder = search
+	p.ponderGame = game
+	return nil
+}
+
+func (p *EnginePlayer) GameOver(ctx context.Context, _ *clock.Game) error {
+	p.ponderMove = maybe.None[chess.Move]()
+	p.stopPonder(ctx)
+	if s := p.e.CurSearch(); s != nil {
+		if err := s.Stop(ctx, true); err != nil {
+			return fmt.Errorf("stop search: %w", err)
+		}
+	}
+	return nil
+}
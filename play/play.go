@@ -0,0 +1,205 @@
+// Package play runs chess games between players of different kinds: UCI engines, humans, random
+// movers and scripted move lists.
+package play
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+type Request struct {
+	// Current game. The player must not modify it.
+	Game *clock.Game
+
+	// Moment when the player forfeits on time. None if the game has no time control.
+	Deadline maybe.Maybe[time.Time]
+
+	// The opponent offered a draw together with the last move.
+	DrawOffered bool
+}
+
+type Response struct {
+	// Move to make. Ignored if Resign or AcceptDraw is set.
+	Move chess.Move
+
+	// Resign the game.
+	Resign bool
+
+	// Offer a draw together with the move.
+	OfferDraw bool
+
+	// Accept the draw offered by the opponent. Ignored if there is no draw offer.
+	AcceptDraw bool
+
+	// Score of the position from the player's point of view, if known. Used for adjudication.
+	Score maybe.Maybe[uci.Score]
+}
+
+type Player interface {
+	// Play returns the action of the player in the current position. The context is cancelled
+	// when the time is out.
+	Play(ctx context.Context, req Request) (Response, error)
+
+	// GameOver is called once the game is finished. The player must stop all the background
+	// activity, e.g. pondering.
+	GameOver(ctx context.Context, g *clock.Game) error
+}
+
+// Observer is implemented by players which want to be notified after each move in the game, made
+// either by themselves or by the opponent.
+type Observer interface {
+	OnMove(ctx context.Context, g *clock.Game) error
+}
+
+type Options struct {
+	// Additional time given to the player after the deadline before its context is cancelled. This
+	// allows the engines to stop the search gracefully if they are a bit late.
+	//
+	// Zero means default.
+	Margin time.Duration
+
+	// Called after each move is added to the game.
+	OnMove func(g *clock.Game, side chess.Color, r Response)
+
+	// Rules to finish the game early based on the scores reported by players.
+	Adjudication maybe.Maybe[AdjudicationRules]
+}
+
+func (o Options) Clone() Options {
+	return o
+}
+
+func (o *Options) FillDefaults() {
+	if o.Margin == 0 {
+		o.Margin = time.Millisecond
+	}
+}
+
+type Result struct {
+	Outcome chess.Outcome
+
+	// Errors returned by the players. The player which failed loses the game.
+	Errors [chess.ColorMax]error
+
+	// Reason of adjudication, if the game was adjudicated.
+	Adjudication string
+}
+
+func (r *Result) Err() error {
+	return errors.Join(r.Errors[:]...)
+}
+
+// PlayGame lets the players make moves until the game is finished. An error is returned only if
+// ctx is cancelled, in which case the game is left unfinished. Failures of the players are reported
+// in the result instead.
+func PlayGame(
+	ctx context.Context,
+	game *clock.Game,
+	players [chess.ColorMax]Player,
+	o Options,
+) (res Result, err error) {
+	o = o.Clone()
+	o.FillDefaults()
+
+	var adj *Adjudicator
+	if rules, ok := o.Adjudication.TryGet(); ok {
+		adj, err = NewAdjudicator(rules)
+		if err != nil {
+			return Result{}, fmt.Errorf("adjudication: %w", err)
+		}
+	}
+
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		for c, p := range players {
+			if err := p.GameOver(ctx, game); err != nil && res.Errors[c] == nil {
+				res.Errors[c] = fmt.Errorf("game over: %w", err)
+			}
+		}
+	}()
+
+	fail := func(side chess.Color, verdict chess.Verdict, err error) {
+		res.Errors[side] = err
+		game.UpdateTimer()
+		if !game.IsFinished() {
+			_ = game.Finish(chess.MustWinOutcome(verdict, side.Inv()))
+		}
+	}
+
+	drawOffered := false
+loop:
+	for {
+		game.UpdateTimer()
+		if game.IsFinished() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		side := game.CurSide()
+		req := Request{
+			Game:        game,
+			Deadline:    maybe.Pack(game.Deadline()),
+			DrawOffered: drawOffered,
+		}
+		r, err := func() (Response, error) {
+			ctx := ctx
+			if d, ok := req.Deadline.TryGet(); ok {
+				var cancel func()
+				ctx, cancel = context.WithDeadline(ctx, d.Add(o.Margin))
+				defer cancel()
+			}
+			return players[side].Play(ctx, req)
+		}()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return res, ctxErr
+			}
+			fail(side, chess.VerdictEngineError, fmt.Errorf("play: %w", err))
+			break
+		}
+		switch {
+		case r.Resign:
+			_ = game.Finish(chess.MustWinOutcome(chess.VerdictResign, side.Inv()))
+		case r.AcceptDraw && drawOffered:
+			_ = game.Finish(chess.MustDrawOutcome(chess.VerdictDrawAgreement))
+		default:
+			if err := game.Push(r.Move); err != nil {
+				fail(side, chess.VerdictInvalidMove, fmt.Errorf("bad move %v: %w", r.Move, err))
+				break loop
+			}
+			drawOffered = r.OfferDraw
+			if o.OnMove != nil {
+				o.OnMove(game, side, r)
+			}
+			if adj != nil {
+				if d := adj.Update(game, side, r.Score); d.IsFinished() && !game.IsFinished() {
+					_ = game.Finish(d.Outcome)
+					res.Adjudication = d.Reason
+					break loop
+				}
+			}
+			for c, p := range players {
+				if obs, ok := p.(Observer); ok {
+					if err := obs.OnMove(ctx, game); err != nil {
+						if ctxErr := ctx.Err(); ctxErr != nil {
+							return res, ctxErr
+						}
+						err = fmt.Errorf("observe move: %w", err)
+						fail(chess.Color(c), chess.VerdictEngineError, err)
+						break loop
+					}
+				}
+			}
+		}
+	}
+	res.Outcome = game.Outcome()
+	return res, nil
+}
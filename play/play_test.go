@@ -0,0 +1,203 @@
+package play
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGame(control maybe.Maybe[clock.Control]) *clock.Game {
+	return clock.NewGame(chess.NewGame(), control, clock.GameOptions{
+		OutcomeFilter: maybe.Some(chess.VerdictFilterRelaxed),
+	})
+}
+
+func TestRandom(t *testing.T) {
+	ctx := context.Background()
+	game := newGame(maybe.None[clock.Control]())
+	res, err := PlayGame(ctx, game, [chess.ColorMax]Player{
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+		NewRandomPlayer(rand.New(rand.NewPCG(3, 4))),
+	}, Options{})
+	require.NoError(t, err)
+	assert.NoError(t, res.Err())
+	assert.True(t, res.Outcome.IsFinished())
+	assert.Equal(t, game.Outcome(), res.Outcome)
+}
+
+func TestScripted(t *testing.T) {
+	ctx := context.Background()
+	script := "f2f3 e7e5 g2g4 d8h4"
+
+	newPlayer := func() Player {
+		p, err := NewScriptedPlayerFromUCI(chess.InitialBoard(), script, nil)
+		require.NoError(t, err)
+		return p
+	}
+	game := newGame(maybe.None[clock.Control]())
+	res, err := PlayGame(ctx, game, [chess.ColorMax]Player{newPlayer(), newPlayer()}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictCheckmate, chess.ColorBlack), res.Outcome)
+
+	// Scripted player without fallback resigns when the opponent deviates.
+	game = newGame(maybe.None[clock.Control]())
+	res, err = PlayGame(ctx, game, [chess.ColorMax]Player{
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+		newPlayer(),
+	}, Options{})
+	require.NoError(t, err)
+	if game.Inner().UCIList() != "f2f3" {
+		assert.Equal(t, chess.MustWinOutcome(chess.VerdictResign, chess.ColorWhite), res.Outcome)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	white := NewTerminalPlayer(strings.NewReader("e4\nNf3 draw\n"), &out, TerminalPlayerOptions{})
+	black := NewTerminalPlayer(strings.NewReader("Nf3\ne7e5\naccept\n"), &out, TerminalPlayerOptions{})
+	game := newGame(maybe.None[clock.Control]())
+	var offers []bool
+	res, err := PlayGame(ctx, game, [chess.ColorMax]Player{white, black}, Options{
+		OnMove: func(_ *clock.Game, _ chess.Color, r Response) { offers = append(offers, r.OfferDraw) },
+	})
+	require.NoError(t, err)
+	assert.NoError(t, res.Err())
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAgreement), res.Outcome)
+	assert.Equal(t, "e2e4 e7e5 g1f3", game.Inner().UCIList())
+	assert.Equal(t, []bool{false, false, true}, offers)
+	assert.Contains(t, out.String(), "error: bad move \"Nf3\"")
+
+	// Input is closed, so the player fails and loses.
+	white = NewTerminalPlayer(strings.NewReader(""), &out, TerminalPlayerOptions{})
+	black = NewTerminalPlayer(strings.NewReader("resign\n"), &out, TerminalPlayerOptions{})
+	game = newGame(maybe.None[clock.Control]())
+	res, err = PlayGame(ctx, game, [chess.ColorMax]Player{white, black}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictEngineError, chess.ColorBlack), res.Outcome)
+	assert.Error(t, res.Errors[chess.ColorWhite])
+	assert.NoError(t, res.Errors[chess.ColorBlack])
+}
+
+func TestEngine(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var fakes [chess.ColorMax]*ucitest.Engine
+	var players [chess.ColorMax]Player
+	for c := range chess.ColorMax {
+		fakes[c] = ucitest.NewEngine(ucitest.EngineOptions{
+			Options: []string{"option name Ponder type check default false"},
+		})
+		e := uci.NewEngine(ctx, fakes[c], nil, uci.EngineOptions{})
+		defer e.Close()
+		require.NoError(t, e.WaitInitialized(ctx))
+		require.NoError(t, e.SetPonder(ctx, true))
+		players[c] = NewEnginePlayer(e, EnginePlayerOptions{Ponder: true})
+	}
+
+	control, err := clock.ControlFromString("40/60")
+	require.NoError(t, err)
+	game := newGame(maybe.Some(control))
+	res, err := PlayGame(ctx, game, players, Options{})
+	require.NoError(t, err)
+	assert.NoError(t, res.Err())
+	assert.True(t, res.Outcome.IsFinished())
+
+	for c := range chess.ColorMax {
+		received := fakes[c].Received()
+		assert.True(t, slices.Contains(received, "ponderhit"), c.LongString())
+		assert.False(t, fakes[c].Searching(), c.LongString())
+	}
+	assert.NotNil(t, players[chess.ColorWhite].(*EnginePlayer).LastSearch())
+}
+
+func TestEnginePerMove(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var fakes [chess.ColorMax]*ucitest.Engine
+	var players [chess.ColorMax]Player
+	for c := range chess.ColorMax {
+		fakes[c] = ucitest.NewEngine(ucitest.EngineOptions{
+			Options: []string{"option name Ponder type check default false"},
+		})
+		e := uci.NewEngine(ctx, fakes[c], nil, uci.EngineOptions{})
+		defer e.Close()
+		require.NoError(t, e.WaitInitialized(ctx))
+		require.NoError(t, e.SetPonder(ctx, true))
+		players[c] = NewEnginePlayer(e, EnginePlayerOptions{Ponder: true})
+	}
+
+	control, err := clock.ControlFromString("s60")
+	require.NoError(t, err)
+	game := newGame(maybe.Some(control))
+	res, err := PlayGame(ctx, game, players, Options{})
+	require.NoError(t, err)
+	assert.NoError(t, res.Err())
+
+	for c := range chess.ColorMax {
+		received := fakes[c].Received()
+		assert.True(t, slices.ContainsFunc(received, func(s string) bool {
+			return strings.HasPrefix(s, "go movetime ")
+		}), c.LongString())
+		assert.False(t, slices.Contains(received, "ponderhit"), c.LongString())
+	}
+}
+
+func TestEngineOverrun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The engine ignores the time limits in the first game and searches until stopped.
+	overrun := true
+	fake := ucitest.NewEngine(ucitest.EngineOptions{
+		Hook: func(e *ucitest.Engine, line string) bool {
+			if overrun && strings.HasPrefix(line, "go ") {
+				e.Process("go infinite")
+				return true
+			}
+			return false
+		},
+	})
+	e := uci.NewEngine(ctx, fake, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+	engine := NewEnginePlayer(e, EnginePlayerOptions{})
+
+	control, err := clock.ControlFromString("0.1")
+	require.NoError(t, err)
+	game := newGame(maybe.Some(control))
+	res, err := PlayGame(ctx, game, [chess.ColorMax]Player{
+		engine,
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+	}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictTimeForfeit, chess.ColorBlack), res.Outcome)
+	assert.Error(t, res.Errors[chess.ColorWhite])
+	assert.Nil(t, e.CurSearch())
+	assert.False(t, fake.Searching())
+
+	overrun = false
+	game = newGame(maybe.None[clock.Control]())
+	res, err = PlayGame(ctx, game, [chess.ColorMax]Player{
+		engine,
+		NewRandomPlayer(rand.New(rand.NewPCG(1, 2))),
+	}, Options{})
+	require.NoError(t, err)
+	assert.NoError(t, res.Err())
+	assert.True(t, res.Outcome.IsFinished())
+}
@@ -0,0 +1,104 @@
+package play
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+)
+
+// RandomPlayer makes uniformly random legal moves.
+type RandomPlayer struct {
+	r *rand.Rand
+}
+
+var _ Player = (*RandomPlayer)(nil)
+
+// NewRandomPlayer creates a random player. If r is nil, then the global random generator is used.
+func NewRandomPlayer(r *rand.Rand) *RandomPlayer {
+	return &RandomPlayer{r: r}
+}
+
+func (p *RandomPlayer) intN(n int) int {
+	if p.r == nil {
+		return rand.IntN(n)
+	}
+	return p.r.IntN(n)
+}
+
+func (p *RandomPlayer) Play(_ context.Context, req Request) (Response, error) {
+	var buf [256]chess.Move
+	moves := req.Game.CurBoard().GenLegalMoves(chess.MoveGenAll, buf[:0])
+	if len(moves) == 0 {
+		return Response{}, fmt.Errorf("no legal moves")
+	}
+	return Response{Move: moves[p.intN(len(moves))]}, nil
+}
+
+func (p *RandomPlayer) GameOver(context.Context, *clock.Game) error { return nil }
+
+// ScriptedPlayer makes the moves from the predefined list. Once the list is exhausted or the
+// opponent deviates from the script, the fallback player is used. If there is no fallback player,
+// then the scripted player resigns.
+//
+// The script may be used as an opening book, with an engine as a fallback.
+type ScriptedPlayer struct {
+	script   *chess.Game
+	fallback Player
+}
+
+var _ Player = (*ScriptedPlayer)(nil)
+
+// NewScriptedPlayer creates a scripted player. The script contains the moves of both sides,
+// starting from the initial position of the game.
+func NewScriptedPlayer(script *chess.Game, fallback Player) *ScriptedPlayer {
+	return &ScriptedPlayer{script: script.Clone(), fallback: fallback}
+}
+
+// NewScriptedPlayerFromUCI creates a scripted player from space-separated moves in UCI format,
+// which start from the given position.
+func NewScriptedPlayerFromUCI(b *chess.Board, ucis string, fallback Player) (*ScriptedPlayer, error) {
+	script, err := chess.GameFromUCIList(b, ucis)
+	if err != nil {
+		return nil, fmt.Errorf("parse script: %w", err)
+	}
+	return &ScriptedPlayer{script: script, fallback: fallback}, nil
+}
+
+func (p *ScriptedPlayer) nextMove(g *chess.Game) (chess.Move, bool) {
+	if g.StartPos() != p.script.StartPos() || g.Len() >= p.script.Len() {
+		return chess.Move{}, false
+	}
+	for i := range g.Len() {
+		if g.MoveAt(i) != p.script.MoveAt(i) {
+			return chess.Move{}, false
+		}
+	}
+	return p.script.MoveAt(g.Len()), true
+}
+
+func (p *ScriptedPlayer) Play(ctx context.Context, req Request) (Response, error) {
+	if mv, ok := p.nextMove(req.Game.Inner()); ok {
+		return Response{Move: mv}, nil
+	}
+	if p.fallback == nil {
+		return Response{Resign: true}, nil
+	}
+	return p.fallback.Play(ctx, req)
+}
+
+func (p *ScriptedPlayer) OnMove(ctx context.Context, g *clock.Game) error {
+	if obs, ok := p.fallback.(Observer); ok {
+		return obs.OnMove(ctx, g)
+	}
+	return nil
+}
+
+func (p *ScriptedPlayer) GameOver(ctx context.Context, g *clock.Game) error {
+	if p.fallback == nil {
+		return nil
+	}
+	return p.fallback.GameOver(ctx, g)
+}
@@ -0,0 +1,145 @@
+package play
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+)
+
+type TerminalPlayerOptions struct {
+	// Style in which the board is shown before each move.
+	BoardStyle chess.PrettyStyle
+}
+
+func (o TerminalPlayerOptions) Clone() TerminalPlayerOptions {
+	return o
+}
+
+// TerminalPlayer is a human who enters the moves in the terminal. The moves are accepted both in
+// SAN and UCI format. Additionally, the following commands are supported:
+//
+//   - "resign" to resign the game
+//   - "accept" to accept the draw offered by the opponent
+//   - "<move> draw" to make the move and offer a draw
+type TerminalPlayer struct {
+	out io.Writer
+	o   TerminalPlayerOptions
+
+	once  sync.Once
+	in    *bufio.Scanner
+	lines chan string
+	err   error
+}
+
+var _ Player = (*TerminalPlayer)(nil)
+
+func NewTerminalPlayer(in io.Reader, out io.Writer, o TerminalPlayerOptions) *TerminalPlayer {
+	return &TerminalPlayer{
+		out:   out,
+		o:     o.Clone(),
+		in:    bufio.NewScanner(in),
+		lines: make(chan string),
+	}
+}
+
+func (p *TerminalPlayer) readLoop() {
+	defer close(p.lines)
+	for p.in.Scan() {
+		p.lines <- p.in.Text()
+	}
+	p.err = p.in.Err()
+}
+
+func (p *TerminalPlayer) readLine(ctx context.Context) (string, error) {
+	// Reading cannot be interrupted, so it's done in a separate goroutine. It's started only once
+	// and lives until the input is closed.
+	p.once.Do(func() { go p.readLoop() })
+	select {
+	case s, ok := <-p.lines:
+		if !ok {
+			if p.err != nil {
+				return "", fmt.Errorf("read: %w", p.err)
+			}
+			return "", io.EOF
+		}
+		return s, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (p *TerminalPlayer) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.out, format, args...)
+}
+
+func parseHumanMove(s string, b *chess.Board) (chess.Move, error) {
+	if mv, err := chess.LegalMoveFromUCI(s, b); err == nil {
+		return mv, nil
+	}
+	mv, err := chess.LegalMoveFromSAN(s, b)
+	if err != nil {
+		return chess.Move{}, fmt.Errorf("bad move %q", s)
+	}
+	return mv, nil
+}
+
+func (p *TerminalPlayer) parse(line string, req Request) (Response, error) {
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) == 1 && fields[0] == "resign":
+		return Response{Resign: true}, nil
+	case len(fields) == 1 && fields[0] == "accept":
+		if !req.DrawOffered {
+			return Response{}, fmt.Errorf("no draw offer to accept")
+		}
+		return Response{AcceptDraw: true}, nil
+	case len(fields) == 1:
+		mv, err := parseHumanMove(fields[0], req.Game.CurBoard())
+		if err != nil {
+			return Response{}, err
+		}
+		return Response{Move: mv}, nil
+	case len(fields) == 2 && fields[1] == "draw":
+		mv, err := parseHumanMove(fields[0], req.Game.CurBoard())
+		if err != nil {
+			return Response{}, err
+		}
+		return Response{Move: mv, OfferDraw: true}, nil
+	default:
+		return Response{}, fmt.Errorf("cannot understand %q", line)
+	}
+}
+
+func (p *TerminalPlayer) Play(ctx context.Context, req Request) (Response, error) {
+	p.printf("%v\n", req.Game.CurBoard().Pretty(p.o.BoardStyle))
+	if c, ok := req.Game.Clock(); ok {
+		p.printf("clock: %v - %v\n", c.White, c.Black)
+	}
+	if req.DrawOffered {
+		p.printf("opponent offers a draw, type \"accept\" to accept it\n")
+	}
+	for {
+		p.printf("%v> ", req.Game.CurSide().LongString())
+		line, err := p.readLine(ctx)
+		if err != nil {
+			return Response{}, err
+		}
+		r, err := p.parse(line, req)
+		if err != nil {
+			p.printf("error: %v\n", err)
+			continue
+		}
+		return r, nil
+	}
+}
+
+func (p *TerminalPlayer) GameOver(_ context.Context, g *clock.Game) error {
+	p.printf("%v\n%v\n", g.CurBoard().Pretty(p.o.BoardStyle), g.Outcome())
+	return nil
+}
@@ -0,0 +1,89 @@
+package puzzle
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var csvHeader = []string{
+	"PuzzleId", "FEN", "Moves", "Rating", "RatingDeviation", "Popularity", "NbPlays", "Themes",
+	"GameUrl", "OpeningTags",
+}
+
+// CSVWriter writes the puzzles in the format of Lichess puzzle database. Rating and popularity
+// columns are left empty, as they are not known for the freshly mined puzzles.
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (w *CSVWriter) Write(p *Puzzle) error {
+	if !w.header {
+		if err := w.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+		w.header = true
+	}
+	themes := make([]string, len(p.Motifs))
+	for i, m := range p.Motifs {
+		themes[i] = string(m)
+	}
+	return w.w.Write([]string{
+		p.ID, p.Start.FEN(), p.UCIList(), "", "", "", "", strings.Join(themes, " "), p.GameURL, "",
+	})
+}
+
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// CSVReader reads the puzzles in the format of Lichess puzzle database. Only the identifier, the
+// position, the moves, the themes and the game URL are used.
+type CSVReader struct {
+	r     *csv.Reader
+	first bool
+}
+
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &CSVReader{r: cr, first: true}
+}
+
+// Read returns io.EOF when there are no more puzzles.
+func (r *CSVReader) Read() (Puzzle, error) {
+	rec, err := r.r.Read()
+	if err != nil {
+		return Puzzle{}, err
+	}
+	if r.first {
+		r.first = false
+		if len(rec) != 0 && rec[0] == csvHeader[0] {
+			return r.Read()
+		}
+	}
+	if len(rec) < 3 {
+		return Puzzle{}, fmt.Errorf("too few fields")
+	}
+	p, err := FromUCI(rec[1], rec[2])
+	if err != nil {
+		return Puzzle{}, fmt.Errorf("puzzle %q: %w", rec[0], err)
+	}
+	p.ID = rec[0]
+	if len(rec) > 7 {
+		for _, t := range strings.Fields(rec[7]) {
+			p.Motifs = append(p.Motifs, Motif(t))
+		}
+	}
+	if len(rec) > 8 {
+		p.GameURL = rec[8]
+	}
+	return p, nil
+}
@@ -0,0 +1,265 @@
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alex65536/go-chess/analysis"
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+)
+
+type Options struct {
+	// Search limits for each position, like depth, nodes or movetime.
+	GoOptions uci.GoOptions
+
+	// Minimum score in centipawns for the solver's best move to be considered decisive, unless it
+	// leads to mate. Also, the solution is trimmed once the solver gained this much material.
+	//
+	// Zero means default.
+	Win int32
+
+	// Minimum difference of win probability between the best and the second best moves, so that
+	// the best move is considered unique.
+	//
+	// Zero means default.
+	Gap float64
+
+	// Maximum number of the solver's moves.
+	//
+	// Zero means default.
+	MaxMoves int
+}
+
+func (o Options) Clone() Options {
+	o.GoOptions = o.GoOptions.Clone()
+	return o
+}
+
+func (o *Options) FillDefaults() {
+	if o.Win == 0 {
+		o.Win = 300
+	}
+	if o.Gap == 0 {
+		o.Gap = 0.3
+	}
+	if o.MaxMoves == 0 {
+		o.MaxMoves = 8
+	}
+}
+
+func (o *Options) Validate() error {
+	g := &o.GoOptions
+	if g.Depth.IsNone() && g.Nodes.IsNone() && g.Movetime.IsNone() {
+		return fmt.Errorf("no search limit")
+	}
+	if g.Infinite || g.Ponder {
+		return fmt.Errorf("search must be finite")
+	}
+	if o.Win < 0 {
+		return fmt.Errorf("negative win score")
+	}
+	if o.Gap < 0 || o.Gap > 1 {
+		return fmt.Errorf("bad gap")
+	}
+	if o.MaxMoves < 0 {
+		return fmt.Errorf("negative max moves")
+	}
+	return nil
+}
+
+type line struct {
+	score uci.Score
+	move  chess.Move
+}
+
+type miner struct {
+	e *uci.Engine
+	o Options
+}
+
+// Runs the search with two principal variations and returns the lines from best to worst.
+func (m *miner) search(ctx context.Context, g *chess.Game) ([]line, error) {
+	if err := m.e.SetPosition(ctx, g); err != nil {
+		return nil, fmt.Errorf("set position: %w", err)
+	}
+	var mu sync.Mutex
+	lines := make(map[int]uci.Info)
+	search, err := m.e.Go(ctx, m.o.GoOptions, func(_ *uci.Search, info uci.Info) {
+		if info.Score.IsNone() || len(info.PV) == 0 {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		lines[info.MultiPV.GetOr(1)] = info
+	})
+	if err != nil {
+		return nil, fmt.Errorf("go: %w", err)
+	}
+	if err := search.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("wait: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	b := g.CurBoard()
+	var res []line
+	for i := 1; i <= 2; i++ {
+		info, ok := lines[i]
+		if !ok {
+			break
+		}
+		mv, err := chess.LegalMoveFromUCIMove(info.PV[0], b)
+		if err != nil {
+			return nil, fmt.Errorf("bad pv move: %w", err)
+		}
+		res = append(res, line{score: info.Score.Get().Score, move: mv})
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no lines")
+	}
+	return res, nil
+}
+
+func (m *miner) isDecisive(s uci.Score) bool {
+	if s.IsMate() {
+		return s.IsWinMate()
+	}
+	cp, _ := s.Centipawns()
+	return cp >= m.o.Win
+}
+
+func (m *miner) isUnique(lines []line) bool {
+	if len(lines) < 2 {
+		// Only one legal move is not interesting.
+		return false
+	}
+	if mate, ok := lines[1].score.Mate(); ok && mate == 1 {
+		// Several mates at the last step are fine, as any mating move is accepted by Session.
+		return true
+	}
+	gap := analysis.WinProbability(lines[0].score) - analysis.WinProbability(lines[1].score)
+	return gap >= m.o.Gap
+}
+
+var errNoPuzzle = errors.New("no puzzle")
+
+// Builds the solution from the given position. Returns errNoPuzzle if the position doesn't
+// contain a puzzle.
+func (m *miner) solve(ctx context.Context, g *chess.Game) ([]chess.Move, uci.Score, error) {
+	g = g.Clone()
+	solver := g.CurBoard().Side()
+	startMaterial := material(g.CurBoard(), solver)
+	var (
+		solution  []chess.Move
+		lastScore uci.Score
+	)
+	for {
+		lines, err := m.search(ctx, g)
+		if err != nil {
+			return nil, uci.Score{}, err
+		}
+		best := lines[0]
+		if !m.isDecisive(best.score) || !m.isUnique(lines) {
+			if len(solution) == 0 || lastScore.IsMate() {
+				return nil, uci.Score{}, errNoPuzzle
+			}
+			// The win is already clear, but the solver has several good continuations, so drop the
+			// opponent's reply and stop here.
+			return solution[:len(solution)-1], lastScore, nil
+		}
+		if len(solution)/2 >= m.o.MaxMoves {
+			return nil, uci.Score{}, errNoPuzzle
+		}
+		g.PushLegalMove(best.move)
+		solution = append(solution, best.move)
+		lastScore = best.score
+
+		b := g.CurBoard()
+		if !b.HasLegalMoves() {
+			if b.IsCheck() {
+				return solution, lastScore, nil
+			}
+			return nil, uci.Score{}, errNoPuzzle
+		}
+
+		lines, err = m.search(ctx, g)
+		if err != nil {
+			return nil, uci.Score{}, err
+		}
+		reply := lines[0].move
+		gained := material(b, solver) - startMaterial
+		if !best.score.IsMate() && gained >= m.o.Win && !isCapture(b, reply) {
+			return solution, lastScore, nil
+		}
+		g.PushLegalMove(reply)
+		solution = append(solution, reply)
+	}
+}
+
+// Mine searches for puzzles in the analyzed game. The puzzle starts after the opponent's mistake
+// or blunder, when the solver has exactly one decisive move on each step of the solution. The
+// engine must support "MultiPV" option.
+func Mine(
+	ctx context.Context,
+	e *uci.Engine,
+	r *analysis.Report,
+	o Options,
+) (_ []Puzzle, err error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	multiPV, ok := e.GetOpt("MultiPV").(*uci.OptionSpin)
+	if !ok {
+		return nil, fmt.Errorf("engine doesn't support multipv")
+	}
+	prevMultiPV := multiPV.IntValue()
+	if err := e.SetOption(ctx, "MultiPV", uci.OptValueInt(2)); err != nil {
+		return nil, fmt.Errorf("set multipv: %w", err)
+	}
+	defer func() {
+		// Restore the option even if ctx is already cancelled.
+		setErr := e.SetOption(context.WithoutCancel(ctx), "MultiPV", uci.OptValueInt(prevMultiPV))
+		if setErr != nil && err == nil {
+			err = fmt.Errorf("restore multipv: %w", setErr)
+		}
+	}()
+
+	m := &miner{e: e, o: o}
+	src := r.Game()
+	start, err := chess.NewBoard(src.StartPos())
+	if err != nil {
+		return nil, fmt.Errorf("bad start position: %w", err)
+	}
+	g := chess.NewGameWithPosition(start)
+	var res []Puzzle
+	for i := 1; i < len(r.Moves); i++ {
+		prev := g.CurBoard().Raw()
+		g.PushLegalMove(src.MoveAt(i - 1))
+		if r.Moves[i-1].Class < analysis.ClassMistake || !m.isDecisive(r.Moves[i].Best) {
+			continue
+		}
+		solution, score, err := m.solve(ctx, g)
+		if err != nil {
+			if errors.Is(err, errNoPuzzle) {
+				continue
+			}
+			return nil, fmt.Errorf("position #%v: %w", i, err)
+		}
+		cp, _ := score.Centipawns()
+		p := Puzzle{
+			Start:  prev,
+			Moves:  append([]chess.Move{src.MoveAt(i - 1)}, solution...),
+			Score:  score,
+			Motifs: findMotifs(g.CurBoard(), solution, cp, score.IsMate()),
+			Ply:    i - 1,
+		}
+		p.ID = makeID(&p)
+		res = append(res, p)
+	}
+	return res, nil
+}
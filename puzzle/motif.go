@@ -0,0 +1,164 @@
+package puzzle
+
+import (
+	"fmt"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+// Motif is a puzzle theme, named as on Lichess.
+type Motif string
+
+const (
+	MotifMate      Motif = "mate"
+	MotifFork      Motif = "fork"
+	MotifPin       Motif = "pin"
+	MotifCrushing  Motif = "crushing"
+	MotifAdvantage Motif = "advantage"
+	MotifOneMove   Motif = "oneMove"
+	MotifShort     Motif = "short"
+	MotifLong      Motif = "long"
+	MotifVeryLong  Motif = "veryLong"
+)
+
+// MotifMateIn returns the motif for mate in n moves, like "mateIn2".
+func MotifMateIn(n int) Motif {
+	return Motif(fmt.Sprintf("mateIn%v", n))
+}
+
+var pieceValues = [...]int32{
+	chess.PiecePawn:   100,
+	chess.PieceKnight: 300,
+	chess.PieceBishop: 300,
+	chess.PieceRook:   500,
+	chess.PieceQueen:  900,
+	chess.PieceKing:   0,
+}
+
+// Returns the material balance in centipawns from c's point of view.
+func material(b *chess.Board, c chess.Color) int32 {
+	var res int32
+	for coord := range chess.CoordMax {
+		cell := b.Get(coord)
+		col, ok := cell.Color()
+		if !ok {
+			continue
+		}
+		p, _ := cell.Piece()
+		if col == c {
+			res += pieceValues[p]
+		} else {
+			res -= pieceValues[p]
+		}
+	}
+	return res
+}
+
+func isCapture(b *chess.Board, mv chess.Move) bool {
+	return mv.Kind() == chess.MoveEnpassant || b.Get(mv.Dst()).IsOccupied()
+}
+
+func kingCoord(b *chess.Board, c chess.Color) chess.Coord {
+	return b.BbCell(chess.CellFromParts(c, chess.PieceKing)).GetFirst()
+}
+
+// Checks whether the piece which has just moved to dst attacks at least two valuable enemy
+// pieces. The piece is valuable if it is the king or is more expensive than the attacker.
+func isFork(b *chess.Board, dst chess.Coord) bool {
+	attacker, ok := b.Get(dst).Piece()
+	if !ok {
+		return false
+	}
+	us := b.Side().Inv()
+	cnt := 0
+	bb := b.BbColor(b.Side())
+	for !bb.IsEmpty() {
+		c := bb.Next()
+		p, _ := b.Get(c).Piece()
+		if p != chess.PieceKing && pieceValues[p] <= pieceValues[attacker] {
+			continue
+		}
+		if b.CellAttackers(c, us).Has(dst) {
+			cnt++
+		}
+	}
+	return cnt >= 2
+}
+
+// Returns the pieces of the given side which are pinned to their king.
+func pinned(b *chess.Board, side chess.Color) chess.Bitboard {
+	raw := b.Raw()
+	raw.Side = side
+	raw.EpSource = chess.NoCoord
+	base, err := chess.NewBoard(raw)
+	if err != nil || base.IsCheck() {
+		return 0
+	}
+	var res chess.Bitboard
+	bb := b.BbColor(side).Without(kingCoord(b, side))
+	for !bb.IsEmpty() {
+		c := bb.Next()
+		r := raw
+		r.Cells[c] = chess.CellEmpty
+		nb, err := chess.NewBoard(r)
+		if err != nil {
+			continue
+		}
+		if nb.IsCheck() {
+			res.Set(c)
+		}
+	}
+	return res
+}
+
+// Finds the motifs of the puzzle. The board must be the position after the opponent's mistake.
+func findMotifs(b *chess.Board, solution []chess.Move, score int32, mate bool) []Motif {
+	var res []Motif
+	b = b.Clone()
+	fork, pin := false, false
+	for i, mv := range solution {
+		if i%2 == 1 {
+			_ = b.MakeLegalMove(mv)
+			continue
+		}
+		them := b.Side().Inv()
+		pinnedBefore := pinned(b, them)
+		_ = b.MakeLegalMove(mv)
+		if isFork(b, mv.Dst()) {
+			fork = true
+		}
+		if pinned(b, them)&^pinnedBefore != 0 {
+			pin = true
+		}
+	}
+	n := (len(solution) + 1) / 2
+	if mate {
+		res = append(res, MotifMate, MotifMateIn(n))
+	}
+	if fork {
+		res = append(res, MotifFork)
+	}
+	if pin {
+		res = append(res, MotifPin)
+	}
+	if !mate {
+		if score >= crushingScore {
+			res = append(res, MotifCrushing)
+		} else {
+			res = append(res, MotifAdvantage)
+		}
+	}
+	switch {
+	case n == 1:
+		res = append(res, MotifOneMove)
+	case n == 2:
+		res = append(res, MotifShort)
+	case n == 3:
+		res = append(res, MotifLong)
+	default:
+		res = append(res, MotifVeryLong)
+	}
+	return res
+}
+
+const crushingScore = 600
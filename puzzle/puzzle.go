@@ -0,0 +1,70 @@
+// Package puzzle extracts tactical puzzles from analyzed games, reads and writes them in the format
+// of Lichess puzzle database, and checks the solutions entered by users.
+package puzzle
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+)
+
+// Puzzle follows the Lichess convention: Start is the position before the opponent's mistake, and
+// Moves begin with this mistake, followed by the solution where the solver's moves alternate with
+// the opponent's replies.
+type Puzzle struct {
+	ID    string
+	Start chess.RawBoard
+	Moves []chess.Move
+
+	// Score after the solution from the solver's point of view.
+	Score uci.Score
+
+	Motifs []Motif
+
+	// Index of the opponent's mistake in the source game.
+	Ply int
+
+	// Link to the source game, if any.
+	GameURL string
+}
+
+// Solver returns the side which solves the puzzle.
+func (p *Puzzle) Solver() chess.Color {
+	return p.Start.Side.Inv()
+}
+
+// SolutionLen returns the number of moves to be found by the solver.
+func (p *Puzzle) SolutionLen() int {
+	return len(p.Moves) / 2
+}
+
+func (p *Puzzle) UCIList() string {
+	var b strings.Builder
+	for i, mv := range p.Moves {
+		if i != 0 {
+			_ = b.WriteByte(' ')
+		}
+		_, _ = b.WriteString(mv.UCI())
+	}
+	return b.String()
+}
+
+const idAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Generates a five-character identifier, like the ones used on Lichess. The identifier depends
+// only on the position and the moves, so the same puzzle mined twice gets the same identifier.
+func makeID(p *Puzzle) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(p.Start.FEN()))
+	_, _ = h.Write([]byte{'|'})
+	_, _ = h.Write([]byte(p.UCIList()))
+	v := h.Sum64()
+	var b [5]byte
+	for i := range b {
+		b[i] = idAlphabet[v%uint64(len(idAlphabet))]
+		v /= uint64(len(idAlphabet))
+	}
+	return string(b[:])
+}
@@ -0,0 +1,176 @@
+package puzzle
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alex65536/go-chess/analysis"
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mateValue = 100000
+
+// Tiny full-width search with material evaluation, which is enough to find simple tactics.
+func negamax(b *chess.Board, depth int) int32 {
+	moves := b.GenLegalMoves(chess.MoveGenAll, nil)
+	if len(moves) == 0 {
+		if b.IsCheck() {
+			return -mateValue
+		}
+		return 0
+	}
+	if depth == 0 {
+		return material(b, b.Side())
+	}
+	best := int32(-mateValue)
+	for _, mv := range moves {
+		u := b.MakeLegalMove(mv)
+		best = max(best, -negamax(b, depth-1))
+		b.UnmakeMove(u)
+	}
+	return best
+}
+
+func searchLines(b *chess.Board, candidates []chess.Move) []ucitest.Line {
+	res := make([]ucitest.Line, 0, len(candidates))
+	for _, mv := range candidates {
+		u := b.MakeLegalMove(mv)
+		v := -negamax(b, 3)
+		b.UnmakeMove(u)
+		score := uci.ScoreCentipawns(v)
+		switch v {
+		case mateValue:
+			score = uci.ScoreMate(1)
+		case -mateValue:
+			score = uci.ScoreMate(-1)
+		}
+		res = append(res, ucitest.Line{Move: mv, Score: score})
+	}
+	slices.SortStableFunc(res, func(a, b ucitest.Line) int {
+		return b.Score.Compare(a.Score)
+	})
+	return res
+}
+
+func TestMine(t *testing.T) {
+	ctx := context.Background()
+	fake := ucitest.NewEngine(ucitest.EngineOptions{
+		Options: []string{"option name MultiPV type spin default 1 min 1 max 16"},
+		Lines:   searchLines,
+	})
+	e := uci.NewEngine(ctx, fake, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+
+	// Black walks into the knight fork.
+	b, err := chess.BoardFromFEN("r4k2/7p/8/1N6/8/8/7P/6K1 b - - 0 1")
+	require.NoError(t, err)
+	g, err := chess.GameFromUCIList(b, "f8e8 b5c7 e8e7 c7a8 e7d7")
+	require.NoError(t, err)
+
+	goOpts := uci.GoOptions{Depth: maybe.Some[int64](3)}
+	r, err := analysis.Analyze(ctx, e, g, analysis.Options{GoOptions: goOpts})
+	require.NoError(t, err)
+	require.Equal(t, analysis.ClassBlunder, r.Moves[0].Class)
+
+	require.NoError(t, e.SetOption(ctx, "MultiPV", uci.OptValueInt(3)))
+	puzzles, err := Mine(ctx, e, r, Options{GoOptions: goOpts})
+	require.NoError(t, err)
+	require.Len(t, puzzles, 1)
+	p := puzzles[0]
+	assert.Equal(t, b.Raw(), p.Start)
+	assert.Equal(t, 0, p.Ply)
+	assert.Equal(t, chess.ColorWhite, p.Solver())
+	assert.Equal(t, 2, p.SolutionLen())
+	assert.True(t, strings.HasPrefix(p.UCIList(), "f8e8 b5c7 "))
+	assert.True(t, strings.HasSuffix(p.UCIList(), " c7a8"))
+	assert.Equal(t, uci.ScoreCentipawns(300), p.Score)
+	assert.Equal(t, []Motif{MotifFork, MotifAdvantage, MotifShort}, p.Motifs)
+	assert.Len(t, p.ID, 5)
+
+	require.NoError(t, e.Ping(ctx))
+	v, ok := fake.Option("MultiPV")
+	assert.True(t, ok)
+	assert.Equal(t, "3", v)
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	require.NoError(t, w.Write(&p))
+	require.NoError(t, w.Flush())
+	assert.Equal(
+		t,
+		"PuzzleId,FEN,Moves,Rating,RatingDeviation,Popularity,NbPlays,Themes,GameUrl,OpeningTags\n"+
+			p.ID+",r4k2/7p/8/1N6/8/8/7P/6K1 b - - 0 1,"+p.UCIList()+",,,,,fork advantage short,,\n",
+		buf.String(),
+	)
+}
+
+func TestMineSeveralMates(t *testing.T) {
+	ctx := context.Background()
+	fake := ucitest.NewEngine(ucitest.EngineOptions{
+		Options: []string{"option name MultiPV type spin default 1 min 1 max 16"},
+		Lines:   searchLines,
+	})
+	e := uci.NewEngine(ctx, fake, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+	require.NoError(t, e.SetOption(ctx, "MultiPV", uci.OptValueInt(2)))
+
+	// Both Ra8# and Rb8# mate.
+	b, err := chess.BoardFromFEN("6k1/5ppp/8/2n5/8/8/8/RR4K1 w - - 0 1")
+	require.NoError(t, err)
+	m := &miner{e: e, o: Options{GoOptions: uci.GoOptions{Depth: maybe.Some[int64](3)}}}
+	m.o.FillDefaults()
+	solution, score, err := m.solve(ctx, chess.NewGameWithPosition(b))
+	require.NoError(t, err)
+	require.Len(t, solution, 1)
+	assert.Equal(t, uci.ScoreMate(1), score)
+	assert.Contains(t, []string{"a1a8", "b1b8"}, solution[0].UCI())
+}
+
+func TestMotifs(t *testing.T) {
+	for _, v := range []struct {
+		fen      string
+		solution string
+		score    int32
+		mate     bool
+		motifs   []Motif
+	}{
+		{
+			fen:      "4k3/8/2n5/8/8/8/8/4KB2 w - - 0 1",
+			solution: "f1b5",
+			score:    100,
+			motifs:   []Motif{MotifPin, MotifAdvantage, MotifOneMove},
+		},
+		{
+			fen:      "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			solution: "a1a8",
+			mate:     true,
+			motifs:   []Motif{MotifMate, MotifMateIn(1), MotifOneMove},
+		},
+		{
+			fen:      "r3k3/8/8/1N6/8/8/8/6K1 w - - 0 1",
+			solution: "b5c7 e8d7 c7a8",
+			score:    800,
+			motifs:   []Motif{MotifFork, MotifCrushing, MotifShort},
+		},
+	} {
+		b, err := chess.BoardFromFEN(v.fen)
+		require.NoError(t, err)
+		g, err := chess.GameFromUCIList(b, v.solution)
+		require.NoError(t, err)
+		var solution []chess.Move
+		for i := range g.Len() {
+			solution = append(solution, g.MoveAt(i))
+		}
+		assert.Equal(t, v.motifs, findMotifs(b, solution, v.score, v.mate), "fen %v", v.fen)
+	}
+}
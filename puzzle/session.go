@@ -0,0 +1,195 @@
+package puzzle
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// FromUCI creates the puzzle from the starting position and the moves in UCI format. The moves
+// follow the Lichess convention, i.e. the first move is made by the opponent.
+func FromUCI(fen string, moves string) (Puzzle, error) {
+	b, err := chess.BoardFromFEN(fen)
+	if err != nil {
+		return Puzzle{}, fmt.Errorf("parse fen: %w", err)
+	}
+	g, err := chess.GameFromUCIList(b, moves)
+	if err != nil {
+		return Puzzle{}, fmt.Errorf("parse moves: %w", err)
+	}
+	p := Puzzle{Start: b.Raw(), Moves: make([]chess.Move, g.Len())}
+	for i := range g.Len() {
+		p.Moves[i] = g.MoveAt(i)
+	}
+	p.ID = makeID(&p)
+	return p, nil
+}
+
+func lineSAN(b *chess.Board, moves []chess.Move) (string, error) {
+	g := chess.NewGameWithPosition(b)
+	for _, mv := range moves {
+		if err := g.PushMove(mv); err != nil {
+			return "", fmt.Errorf("bad move %v: %w", mv, err)
+		}
+	}
+	return g.Styled(chess.GameStyle{
+		Move:       chess.MoveStyleSAN,
+		MoveNumber: chess.MoveNumberStyle{Enabled: true},
+	})
+}
+
+// SolutionSAN returns the solution in SAN, starting from the position after the opponent's move.
+func (p *Puzzle) SolutionSAN() (string, error) {
+	b, err := chess.NewBoard(p.Start)
+	if err != nil {
+		return "", fmt.Errorf("bad start position: %w", err)
+	}
+	if len(p.Moves) == 0 {
+		return "", nil
+	}
+	if _, err := b.MakeMove(p.Moves[0]); err != nil {
+		return "", fmt.Errorf("bad move %v: %w", p.Moves[0], err)
+	}
+	return lineSAN(b, p.Moves[1:])
+}
+
+type State uint8
+
+const (
+	StateRunning State = iota
+	StateSolved
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateSolved:
+		return "solved"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("State(%d)", uint8(s))
+	}
+}
+
+type Feedback struct {
+	Correct bool
+
+	// Opponent's reply, which is played automatically after the correct move.
+	Reply maybe.Maybe[chess.Move]
+
+	State State
+}
+
+// Session lets the user solve the puzzle move by move. The opponent's moves are played
+// automatically. If the puzzle ends in mate, then any mating move is accepted.
+type Session struct {
+	p     Puzzle
+	board *chess.Board
+	next  int
+	state State
+	mate  bool
+}
+
+func NewSession(p *Puzzle) (*Session, error) {
+	if len(p.Moves) < 2 || len(p.Moves)%2 != 0 {
+		return nil, fmt.Errorf("bad number of moves")
+	}
+	b, err := chess.NewBoard(p.Start)
+	if err != nil {
+		return nil, fmt.Errorf("bad start position: %w", err)
+	}
+	end := b.Clone()
+	for i, mv := range p.Moves {
+		if _, err := end.MakeMove(mv); err != nil {
+			return nil, fmt.Errorf("bad move #%v: %w", i+1, err)
+		}
+	}
+	s := &Session{
+		p:     *p,
+		board: b,
+		mate:  end.CalcOutcome().Verdict() == chess.VerdictCheckmate,
+	}
+	s.p.Moves = slices.Clone(p.Moves)
+	s.p.Motifs = slices.Clone(p.Motifs)
+	s.advance()
+	return s, nil
+}
+
+func (s *Session) advance() {
+	_ = s.board.MakeLegalMove(s.p.Moves[s.next])
+	s.next++
+}
+
+func (s *Session) Puzzle() *Puzzle { return &s.p }
+
+// Board returns the current position, in which the user must make a move.
+func (s *Session) Board() *chess.Board { return s.board.Clone() }
+
+func (s *Session) State() State { return s.state }
+
+// Hint returns the expected move. It returns false if the puzzle is over.
+func (s *Session) Hint() (chess.Move, bool) {
+	if s.state != StateRunning {
+		return chess.Move{}, false
+	}
+	return s.p.Moves[s.next], true
+}
+
+// ExpectedLine returns the rest of the solution in SAN, starting from the current position. After
+// a wrong move, it shows how the puzzle had to be solved from this point.
+func (s *Session) ExpectedLine() (string, error) {
+	return lineSAN(s.board, s.p.Moves[s.next:])
+}
+
+// Play checks the user's move. An error is returned if the move is illegal or the puzzle is
+// already over. The wrong move finishes the puzzle as failed and is not applied to the board.
+func (s *Session) Play(mv chess.Move) (Feedback, error) {
+	if s.state != StateRunning {
+		return Feedback{}, fmt.Errorf("puzzle is %v", s.state)
+	}
+	after := s.board.Clone()
+	if _, err := after.MakeMove(mv); err != nil {
+		return Feedback{}, fmt.Errorf("bad move: %w", err)
+	}
+
+	if s.mate && after.CalcOutcome().Verdict() == chess.VerdictCheckmate {
+		s.board = after
+		s.next = len(s.p.Moves)
+		s.state = StateSolved
+		return Feedback{Correct: true, State: s.state}, nil
+	}
+	if mv != s.p.Moves[s.next] {
+		s.state = StateFailed
+		return Feedback{State: s.state}, nil
+	}
+
+	s.advance()
+	if s.next == len(s.p.Moves) {
+		s.state = StateSolved
+		return Feedback{Correct: true, State: s.state}, nil
+	}
+	reply := s.p.Moves[s.next]
+	s.advance()
+	return Feedback{Correct: true, Reply: maybe.Some(reply), State: s.state}, nil
+}
+
+func (s *Session) PlayUCI(str string) (Feedback, error) {
+	mv, err := chess.LegalMoveFromUCI(str, s.board)
+	if err != nil {
+		return Feedback{}, fmt.Errorf("parse move: %w", err)
+	}
+	return s.Play(mv)
+}
+
+func (s *Session) PlaySAN(str string) (Feedback, error) {
+	mv, err := chess.LegalMoveFromSAN(str, s.board)
+	if err != nil {
+		return Feedback{}, fmt.Errorf("parse move: %w", err)
+	}
+	return s.Play(mv)
+}
@@ -0,0 +1,93 @@
+package puzzle
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	p, err := FromUCI("r4k2/7p/8/1N6/8/8/7P/6K1 b - - 0 1", "f8e8 b5c7 e8e7 c7a8")
+	require.NoError(t, err)
+	san, err := p.SolutionSAN()
+	require.NoError(t, err)
+	assert.Equal(t, "2. Nc7+ Ke7 3. Nxa8", san)
+
+	s, err := NewSession(&p)
+	require.NoError(t, err)
+	assert.Equal(t, "r3k3/7p/8/1N6/8/8/7P/6K1 w - - 1 2", s.Board().FEN())
+	hint, ok := s.Hint()
+	assert.True(t, ok)
+	assert.Equal(t, "b5c7", hint.UCI())
+
+	_, err = s.PlayUCI("b5b6")
+	assert.Error(t, err)
+	assert.Equal(t, StateRunning, s.State())
+
+	f, err := s.PlaySAN("Nc7+")
+	require.NoError(t, err)
+	assert.True(t, f.Correct)
+	assert.Equal(t, StateRunning, f.State)
+	assert.Equal(t, "e8e7", f.Reply.Get().UCI())
+
+	f, err = s.PlayUCI("c7a8")
+	require.NoError(t, err)
+	assert.Equal(t, Feedback{Correct: true, State: StateSolved}, f)
+	_, err = s.PlayUCI("a8c7")
+	assert.Error(t, err)
+
+	s, err = NewSession(&p)
+	require.NoError(t, err)
+	f, err = s.PlaySAN("Nd6+")
+	require.NoError(t, err)
+	assert.Equal(t, Feedback{State: StateFailed}, f)
+	line, err := s.ExpectedLine()
+	require.NoError(t, err)
+	assert.Equal(t, "2. Nc7+ Ke7 3. Nxa8", line)
+	_, ok = s.Hint()
+	assert.False(t, ok)
+}
+
+func TestSessionAlternativeMate(t *testing.T) {
+	p, err := FromUCI("7k/6pp/8/8/8/2n5/1R6/R5K1 b - - 0 1", "c3d5 a1a8")
+	require.NoError(t, err)
+
+	s, err := NewSession(&p)
+	require.NoError(t, err)
+	f, err := s.PlayUCI("b2b8")
+	require.NoError(t, err)
+	assert.Equal(t, Feedback{Correct: true, State: StateSolved}, f)
+
+	_, err = NewSession(&Puzzle{Start: p.Start, Moves: p.Moves[:1]})
+	assert.Error(t, err)
+}
+
+func TestCSVRoundtrip(t *testing.T) {
+	p, err := FromUCI("7k/6pp/8/8/8/2n5/1R6/R5K1 b - - 0 1", "c3d5 a1a8")
+	require.NoError(t, err)
+	p.Motifs = []Motif{MotifMate, MotifMateIn(1), MotifOneMove}
+	p.GameURL = "https://example.com/game"
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	require.NoError(t, w.Write(&p))
+	require.NoError(t, w.Write(&p))
+	require.NoError(t, w.Flush())
+
+	r := NewCSVReader(&buf)
+	for range 2 {
+		q, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, p.ID, q.ID)
+		assert.Equal(t, p.Start, q.Start)
+		assert.Equal(t, p.Moves, q.Moves)
+		assert.Equal(t, p.Motifs, q.Motifs)
+		assert.Equal(t, p.GameURL, q.GameURL)
+	}
+	_, err = r.Read()
+	assert.True(t, errors.Is(err, io.EOF))
+}
@@ -0,0 +1,218 @@
+// Package selfplay generates training data for evaluation functions from engine self-play games.
+//
+// Each position reached during the game is recorded together with the engine score and the final
+// result of the game, and the samples are written in either plain text or compact binary format.
+package selfplay
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/play"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// Filter specifies which positions are dropped from the output. By default, positions in check,
+// positions where the best move is a capture or a promotion, and positions with mate scores are
+// dropped, as their static evaluation differs a lot from the search score.
+type Filter struct {
+	KeepCheck    bool
+	KeepTactical bool
+	KeepMate     bool
+
+	// Drop positions with absolute score greater than MaxScore centipawns.
+	//
+	// Zero means no limit.
+	MaxScore int32
+}
+
+func (f *Filter) accept(b *chess.Board, mv chess.Move, score uci.Score) bool {
+	if !f.KeepCheck && b.IsCheck() {
+		return false
+	}
+	if !f.KeepTactical && isTactical(b, mv) {
+		return false
+	}
+	cp, ok := score.Centipawns()
+	if !ok && !f.KeepMate {
+		return false
+	}
+	if f.MaxScore != 0 && (cp > f.MaxScore || cp < -f.MaxScore) {
+		return false
+	}
+	return true
+}
+
+func isTactical(b *chess.Board, mv chess.Move) bool {
+	if _, ok := mv.Kind().Promote(); ok {
+		return true
+	}
+	return mv.Kind() == chess.MoveEnpassant || b.Get(mv.Dst()).IsOccupied()
+}
+
+type Options struct {
+	// Search limits, like depth or nodes. Must not be empty, as the games are played without the
+	// clock.
+	GoOptions uci.GoOptions
+
+	// Starting positions. Game i starts from Openings[i % len(Openings)]. If empty, then the games
+	// start from the initial position. The positions from the openings are not recorded.
+	Openings []*chess.Game
+
+	// Number of games to play.
+	Games int
+
+	Adjudication maybe.Maybe[play.AdjudicationRules]
+
+	Filter Filter
+
+	// Called after each game.
+	OnGame func(s Stats)
+}
+
+func (o Options) Clone() Options {
+	o.GoOptions = o.GoOptions.Clone()
+	o.Openings = slices.Clone(o.Openings)
+	return o
+}
+
+func (o *Options) Validate() error {
+	if o.Games <= 0 {
+		return fmt.Errorf("non-positive games")
+	}
+	g := &o.GoOptions
+	if g.Depth.IsNone() && g.Nodes.IsNone() && g.Movetime.IsNone() {
+		return fmt.Errorf("no search limit")
+	}
+	if g.Infinite || g.Ponder {
+		return fmt.Errorf("search must be finite")
+	}
+	return nil
+}
+
+type Stats struct {
+	Games     int
+	Positions int
+	Samples   int
+
+	// Games discarded due to engine failures.
+	Failed int
+
+	WhiteWins int
+	Draws     int
+	BlackWins int
+}
+
+type record struct {
+	board *chess.Board
+	move  chess.Move
+	score maybe.Maybe[uci.Score]
+}
+
+func playGame(
+	ctx context.Context,
+	e *uci.Engine,
+	opening *chess.Game,
+	o *Options,
+) ([]record, play.Result, error) {
+	if err := e.UCINewGame(ctx, true); err != nil {
+		return nil, play.Result{}, fmt.Errorf("ucinewgame: %w", err)
+	}
+	game := clock.NewGame(opening, maybe.None[clock.Control](), clock.GameOptions{
+		OutcomeFilter: maybe.Some(chess.VerdictFilterRelaxed),
+	})
+	if game.IsFinished() {
+		return nil, play.Result{Outcome: game.Outcome()}, nil
+	}
+	// Both sides use the same engine, but each of them needs its own player, as the player keeps
+	// the search state of its side.
+	var players [chess.ColorMax]play.Player
+	for c := range chess.ColorMax {
+		players[c] = play.NewEnginePlayer(e, play.EnginePlayerOptions{GoOptions: o.GoOptions})
+	}
+
+	var recs []record
+	board := game.CurBoard().Clone()
+	res, err := play.PlayGame(ctx, game, players, play.Options{
+		Adjudication: o.Adjudication,
+		OnMove: func(g *clock.Game, _ chess.Color, r play.Response) {
+			recs = append(recs, record{board: board, move: r.Move, score: r.Score})
+			board = g.CurBoard().Clone()
+		},
+	})
+	if err != nil {
+		return nil, play.Result{}, err
+	}
+	return recs, res, nil
+}
+
+func clampScore(cp int32) int16 {
+	return int16(min(max(cp, math.MinInt16+1), math.MaxInt16))
+}
+
+// Generate plays o.Games self-play games with e and writes the samples into w. Games in which the
+// engine failed are discarded.
+func Generate(ctx context.Context, e *uci.Engine, o Options, w Writer) (Stats, error) {
+	o = o.Clone()
+	if err := o.Validate(); err != nil {
+		return Stats{}, fmt.Errorf("validate: %w", err)
+	}
+
+	var st Stats
+	for i := range o.Games {
+		opening := chess.NewGame()
+		if len(o.Openings) != 0 {
+			opening = o.Openings[i%len(o.Openings)]
+		}
+		recs, res, err := playGame(ctx, e, opening, &o)
+		if err != nil {
+			return st, fmt.Errorf("game %v: %w", i+1, err)
+		}
+		st.Games++
+		if res.Err() != nil {
+			st.Failed++
+			if e.Terminated() {
+				return st, fmt.Errorf("game %v: engine terminated: %w", i+1, res.Err())
+			}
+			continue
+		}
+		status := res.Outcome.Status()
+		switch status {
+		case chess.StatusWhiteWins:
+			st.WhiteWins++
+		case chess.StatusBlackWins:
+			st.BlackWins++
+		case chess.StatusDraw:
+			st.Draws++
+		}
+		for _, r := range recs {
+			st.Positions++
+			score, ok := r.score.TryGet()
+			if !ok || !o.Filter.accept(r.board, r.move, score) {
+				continue
+			}
+			cp, _ := score.Centipawns()
+			v := clampScore(cp)
+			if r.board.Side() == chess.ColorBlack {
+				v = -v
+			}
+			s := Sample{Pos: r.board.Raw(), Score: v, Result: status}
+			if err := w.Write(s); err != nil {
+				return st, fmt.Errorf("write: %w", err)
+			}
+			st.Samples++
+		}
+		if err := w.Flush(); err != nil {
+			return st, fmt.Errorf("flush: %w", err)
+		}
+		if o.OnGame != nil {
+			o.OnGame(st)
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,252 @@
+package selfplay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+// Sample is a training position.
+type Sample struct {
+	Pos chess.RawBoard
+
+	// Score in centipawns from White's point of view.
+	Score int16
+
+	// Final result of the game.
+	Result chess.Status
+}
+
+func resultString(s chess.Status) string {
+	switch s {
+	case chess.StatusWhiteWins:
+		return "1.0"
+	case chess.StatusBlackWins:
+		return "0.0"
+	default:
+		return "0.5"
+	}
+}
+
+func resultFromString(s string) (chess.Status, error) {
+	switch s {
+	case "1.0", "1":
+		return chess.StatusWhiteWins, nil
+	case "0.0", "0":
+		return chess.StatusBlackWins, nil
+	case "0.5":
+		return chess.StatusDraw, nil
+	default:
+		return chess.StatusRunning, fmt.Errorf("bad result %q", s)
+	}
+}
+
+type Writer interface {
+	Write(s Sample) error
+	Flush() error
+}
+
+type Reader interface {
+	// Read returns io.EOF when there are no more samples.
+	Read() (Sample, error)
+}
+
+// TextWriter writes the samples as lines of form "<fen> | <score> | <result>", where the result is
+// 1.0, 0.5 or 0.0 from White's point of view.
+type TextWriter struct {
+	w *bufio.Writer
+}
+
+func NewTextWriter(w io.Writer) *TextWriter {
+	return &TextWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *TextWriter) Write(s Sample) error {
+	_, err := fmt.Fprintf(w.w, "%v | %v | %v\n", s.Pos.FEN(), s.Score, resultString(s.Result))
+	return err
+}
+
+func (w *TextWriter) Flush() error {
+	return w.w.Flush()
+}
+
+type TextReader struct {
+	s *bufio.Scanner
+}
+
+func NewTextReader(r io.Reader) *TextReader {
+	return &TextReader{s: bufio.NewScanner(r)}
+}
+
+func (r *TextReader) Read() (Sample, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return Sample{}, err
+		}
+		return Sample{}, io.EOF
+	}
+	parts := strings.Split(r.s.Text(), "|")
+	if len(parts) != 3 {
+		return Sample{}, fmt.Errorf("bad line %q", r.s.Text())
+	}
+	pos, err := chess.RawBoardFromFEN(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Sample{}, fmt.Errorf("parse fen: %w", err)
+	}
+	score, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 16)
+	if err != nil {
+		return Sample{}, fmt.Errorf("parse score: %w", err)
+	}
+	res, err := resultFromString(strings.TrimSpace(parts[2]))
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{Pos: pos, Score: int16(score), Result: res}, nil
+}
+
+// BinarySampleSize is the size of one sample in the binary format. The sample is encoded as
+// follows (all the integers are little-endian):
+//
+//   - occupancy bitboard (8 bytes), where bit i is set if there is a piece on coord i
+//   - cells of occupied squares in order of increasing coords, two per byte, the first one in the
+//     lower nibble (16 bytes, padded with zeros)
+//   - side to move (1 byte)
+//   - castling rights (1 byte)
+//   - en passant source coord, or 255 if none (1 byte)
+//   - move counter (1 byte)
+//   - move number (2 bytes)
+//   - score (2 bytes)
+//   - result, 0 for Black win, 1 for draw, 2 for White win (1 byte)
+const BinarySampleSize = 33
+
+const maxPieces = 32
+
+func encodeSample(s Sample, buf *[BinarySampleSize]byte) error {
+	*buf = [BinarySampleSize]byte{}
+	var occ uint64
+	n := 0
+	for c, cell := range s.Pos.Cells {
+		if cell.IsFree() {
+			continue
+		}
+		if n == maxPieces {
+			return fmt.Errorf("too many pieces")
+		}
+		occ |= 1 << c
+		buf[8+n/2] |= byte(cell) << (4 * (n % 2))
+		n++
+	}
+	binary.LittleEndian.PutUint64(buf[0:8], occ)
+	buf[24] = byte(s.Pos.Side)
+	buf[25] = byte(s.Pos.Castling)
+	buf[26] = byte(s.Pos.EpSource)
+	buf[27] = s.Pos.MoveCounter
+	if s.Pos.MoveNumber > math.MaxUint16 {
+		return fmt.Errorf("move number too large")
+	}
+	binary.LittleEndian.PutUint16(buf[28:30], uint16(s.Pos.MoveNumber))
+	binary.LittleEndian.PutUint16(buf[30:32], uint16(s.Score))
+	switch s.Result {
+	case chess.StatusBlackWins:
+		buf[32] = 0
+	case chess.StatusDraw:
+		buf[32] = 1
+	case chess.StatusWhiteWins:
+		buf[32] = 2
+	default:
+		return fmt.Errorf("game not finished")
+	}
+	return nil
+}
+
+func decodeSample(buf *[BinarySampleSize]byte) (Sample, error) {
+	var s Sample
+	occ := binary.LittleEndian.Uint64(buf[0:8])
+	n := 0
+	for c := range s.Pos.Cells {
+		if occ&(1<<c) == 0 {
+			continue
+		}
+		if n == maxPieces {
+			return Sample{}, fmt.Errorf("too many pieces")
+		}
+		cell := chess.Cell((buf[8+n/2] >> (4 * (n % 2))) & 0xf)
+		if !cell.IsValid() || cell.IsFree() {
+			return Sample{}, fmt.Errorf("bad cell")
+		}
+		s.Pos.Cells[c] = cell
+		n++
+	}
+	s.Pos.Side = chess.Color(buf[24])
+	s.Pos.Castling = chess.CastlingRights(buf[25])
+	s.Pos.EpSource = chess.MaybeCoord(buf[26])
+	s.Pos.MoveCounter = buf[27]
+	s.Pos.MoveNumber = uint32(binary.LittleEndian.Uint16(buf[28:30]))
+	s.Score = int16(binary.LittleEndian.Uint16(buf[30:32]))
+	switch buf[32] {
+	case 0:
+		s.Result = chess.StatusBlackWins
+	case 1:
+		s.Result = chess.StatusDraw
+	case 2:
+		s.Result = chess.StatusWhiteWins
+	default:
+		return Sample{}, fmt.Errorf("bad result")
+	}
+	if _, err := chess.NewBoard(s.Pos); err != nil {
+		return Sample{}, fmt.Errorf("bad position: %w", err)
+	}
+	return s, nil
+}
+
+// BinaryWriter writes the samples in compact binary format, BinarySampleSize bytes per sample.
+type BinaryWriter struct {
+	w *bufio.Writer
+}
+
+func NewBinaryWriter(w io.Writer) *BinaryWriter {
+	return &BinaryWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *BinaryWriter) Write(s Sample) error {
+	var buf [BinarySampleSize]byte
+	if err := encodeSample(s, &buf); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	_, err := w.w.Write(buf[:])
+	return err
+}
+
+func (w *BinaryWriter) Flush() error {
+	return w.w.Flush()
+}
+
+type BinaryReader struct {
+	r *bufio.Reader
+}
+
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{r: bufio.NewReader(r)}
+}
+
+func (r *BinaryReader) Read() (Sample, error) {
+	var buf [BinarySampleSize]byte
+	if _, err := io.ReadFull(r.r, buf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Sample{}, fmt.Errorf("truncated sample")
+		}
+		return Sample{}, err
+	}
+	s, err := decodeSample(&buf)
+	if err != nil {
+		return Sample{}, fmt.Errorf("decode: %w", err)
+	}
+	return s, nil
+}
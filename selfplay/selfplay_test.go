@@ -0,0 +1,136 @@
+package selfplay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/play"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sliceWriter struct {
+	samples []Sample
+}
+
+func (w *sliceWriter) Write(s Sample) error {
+	w.samples = append(w.samples, s)
+	return nil
+}
+
+func (w *sliceWriter) Flush() error { return nil }
+
+func readAll(t *testing.T, r Reader) []Sample {
+	var res []Sample
+	for {
+		s, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+		require.NoError(t, err)
+		res = append(res, s)
+	}
+}
+
+func testSamples(t *testing.T) []Sample {
+	var res []Sample
+	for _, x := range []struct {
+		fen    string
+		score  int16
+		result chess.Status
+	}{
+		{chess.InitialRawBoard().FEN(), 25, chess.StatusDraw},
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", -13, chess.StatusWhiteWins},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", 32767, chess.StatusBlackWins},
+		{"8/8/4k3/8/8/4K3/4P3/8 b - - 57 312", -32767, chess.StatusDraw},
+	} {
+		b, err := chess.RawBoardFromFEN(x.fen)
+		require.NoError(t, err)
+		res = append(res, Sample{Pos: b, Score: x.score, Result: x.result})
+	}
+	return res
+}
+
+func TestText(t *testing.T) {
+	samples := testSamples(t)
+	var buf bytes.Buffer
+	w := NewTextWriter(&buf)
+	for _, s := range samples {
+		require.NoError(t, w.Write(s))
+	}
+	require.NoError(t, w.Flush())
+	assert.True(t, strings.HasPrefix(
+		buf.String(),
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 | 25 | 0.5\n",
+	))
+	assert.Equal(t, samples, readAll(t, NewTextReader(&buf)))
+}
+
+func TestBinary(t *testing.T) {
+	samples := testSamples(t)
+	var buf bytes.Buffer
+	w := NewBinaryWriter(&buf)
+	for _, s := range samples {
+		require.NoError(t, w.Write(s))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, len(samples)*BinarySampleSize, buf.Len())
+	assert.Equal(t, samples, readAll(t, NewBinaryReader(bytes.NewReader(buf.Bytes()))))
+
+	_, err := NewBinaryReader(bytes.NewReader(buf.Bytes()[:BinarySampleSize-1])).Read()
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	rnd := rand.New(rand.NewPCG(1, 2))
+	fake := ucitest.NewEngine(ucitest.EngineOptions{
+		Choose: func(_ *chess.Board, candidates []chess.Move) chess.Move {
+			return candidates[rnd.IntN(len(candidates))]
+		},
+	})
+	e := uci.NewEngine(ctx, fake, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+
+	opening, err := chess.GameFromUCIList(chess.InitialBoard(), "e2e4 e7e5")
+	require.NoError(t, err)
+
+	var w sliceWriter
+	games := 0
+	st, err := Generate(ctx, e, Options{
+		GoOptions:    uci.GoOptions{Nodes: maybe.Some[int64](1000)},
+		Openings:     []*chess.Game{opening},
+		Games:        3,
+		Adjudication: maybe.Some(play.AdjudicationRules{MaxMoves: maybe.Some(30)}),
+		OnGame:       func(Stats) { games++ },
+	}, &w)
+	require.NoError(t, err)
+	assert.Equal(t, 3, st.Games)
+	assert.Equal(t, 3, games)
+	assert.Equal(t, 0, st.Failed)
+	assert.Equal(t, 3, st.WhiteWins+st.Draws+st.BlackWins)
+	assert.Equal(t, st.Samples, len(w.samples))
+	assert.Greater(t, st.Samples, 0)
+	assert.Less(t, st.Samples, st.Positions)
+
+	for _, s := range w.samples {
+		b, err := chess.NewBoard(s.Pos)
+		require.NoError(t, err)
+		assert.False(t, b.IsCheck())
+		assert.GreaterOrEqual(t, b.MoveNumber(), uint32(2))
+		assert.NotEqual(t, chess.StatusRunning, s.Result)
+	}
+
+	_, err = Generate(ctx, e, Options{Games: 1}, &w)
+	assert.Error(t, err)
+}
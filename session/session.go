@@ -0,0 +1,282 @@
+// Package session implements the rules of online games on top of clock.Game: draw offers,
+// takebacks, aborts, resignations and draw claims.
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+type Options struct {
+	// Function to get the current time, which must be the same as the one used by the game.
+	Now func() time.Time
+
+	// The game can be aborted while it has fewer moves than this. Some(0) disables aborts.
+	//
+	// None means default.
+	AbortMoves maybe.Maybe[int]
+}
+
+func (o *Options) FillDefaults() {
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	if o.AbortMoves.IsNone() {
+		o.AbortMoves = maybe.Some(2)
+	}
+}
+
+type takeback struct {
+	side chess.Color
+	ply  int
+}
+
+// Session is a game between two players, who can negotiate with each other.
+//
+// Draw offer stands until the opponent makes a move, so a side can offer a draw and then make its
+// move. Takeback proposal cancels on any move.
+type Session struct {
+	o Options
+	g *clock.Game
+
+	// Moves made before the session started cannot be taken back.
+	start int
+
+	// timers[i] is the state of the timer when the game had start+i moves. The items are nil if
+	// the game has no timer.
+	timers []*clock.TimerSnapshot
+
+	drawOffer maybe.Maybe[chess.Color]
+	takeback  maybe.Maybe[takeback]
+}
+
+func New(g *clock.Game, o Options) *Session {
+	o.FillDefaults()
+	s := &Session{
+		o:     o,
+		g:     g,
+		start: g.Inner().Len(),
+	}
+	s.save()
+	return s
+}
+
+func (s *Session) save() {
+	var ts *clock.TimerSnapshot
+	if t, ok := s.g.TimerSnapshot(); ok {
+		ts = &t
+	}
+	s.timers = append(s.timers, ts)
+}
+
+// Game returns the current game. It is replaced after takeback, so the returned pointer must not
+// be kept for long.
+func (s *Session) Game() *clock.Game { return s.g }
+
+// DrawOffer returns the side which offered a draw, if any.
+func (s *Session) DrawOffer() (chess.Color, bool) { return s.drawOffer.TryGet() }
+
+// Takeback returns the side which proposed a takeback, if any.
+func (s *Session) Takeback() (chess.Color, bool) {
+	t, ok := s.takeback.TryGet()
+	return t.side, ok
+}
+
+func (s *Session) check() error {
+	s.g.UpdateTimer()
+	if s.g.IsFinished() {
+		return fmt.Errorf("game already finished")
+	}
+	return nil
+}
+
+func (s *Session) finish(o chess.Outcome) error {
+	s.drawOffer = maybe.None[chess.Color]()
+	s.takeback = maybe.None[takeback]()
+	if err := s.g.Finish(o); err != nil {
+		return fmt.Errorf("finish: %w", err)
+	}
+	return nil
+}
+
+// Move makes the move on behalf of the given side. The opponent's draw offer and any takeback
+// proposal are cancelled.
+func (s *Session) Move(c chess.Color, mv chess.Move) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if c != s.g.CurSide() {
+		return fmt.Errorf("not %v's turn", c.LongString())
+	}
+	if err := s.g.Push(mv); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	if side, ok := s.drawOffer.TryGet(); ok && side != c {
+		s.drawOffer = maybe.None[chess.Color]()
+	}
+	s.takeback = maybe.None[takeback]()
+	s.save()
+	return nil
+}
+
+// OfferDraw offers a draw on behalf of the given side. If the opponent has already offered a draw,
+// then the game is drawn by agreement.
+func (s *Session) OfferDraw(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	side, ok := s.drawOffer.TryGet()
+	if ok && side == c {
+		return fmt.Errorf("draw already offered")
+	}
+	if ok {
+		return s.finish(chess.MustDrawOutcome(chess.VerdictDrawAgreement))
+	}
+	s.drawOffer = maybe.Some(c)
+	return nil
+}
+
+func (s *Session) AcceptDraw(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if side, ok := s.drawOffer.TryGet(); !ok || side == c {
+		return fmt.Errorf("no draw offer")
+	}
+	return s.finish(chess.MustDrawOutcome(chess.VerdictDrawAgreement))
+}
+
+func (s *Session) DeclineDraw(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if side, ok := s.drawOffer.TryGet(); !ok || side == c {
+		return fmt.Errorf("no draw offer")
+	}
+	s.drawOffer = maybe.None[chess.Color]()
+	return nil
+}
+
+// ProposeTakeback proposes to take back the last move of the given side. If it is the side's
+// turn, the opponent's reply is taken back as well.
+func (s *Session) ProposeTakeback(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if s.takeback.IsSome() {
+		return fmt.Errorf("takeback already proposed")
+	}
+	ply := s.g.Inner().Len() - 1
+	if c == s.g.CurSide() {
+		ply--
+	}
+	if ply < s.start {
+		return fmt.Errorf("no move to take back")
+	}
+	s.takeback = maybe.Some(takeback{side: c, ply: ply})
+	return nil
+}
+
+// AcceptTakeback takes back the moves. The clocks are restored to the values they had before the
+// first move taken back.
+func (s *Session) AcceptTakeback(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	t, ok := s.takeback.TryGet()
+	if !ok || t.side == c {
+		return fmt.Errorf("no takeback proposal")
+	}
+
+	g, err := s.rebuild(t.ply)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	s.g = g
+	s.timers = s.timers[:t.ply-s.start+1]
+	s.takeback = maybe.None[takeback]()
+	s.drawOffer = maybe.None[chess.Color]()
+	return nil
+}
+
+// Rebuilds the game as it was when it had ply moves.
+func (s *Session) rebuild(ply int) (*clock.Game, error) {
+	inner := s.g.Inner()
+	snap := clock.Snapshot{
+		Start:   inner.StartPos(),
+		Moves:   make([]string, ply),
+		Verdict: chess.VerdictRunning,
+		Filter:  s.g.Filter(),
+	}
+	for i := range ply {
+		snap.Moves[i] = inner.MoveAt(i).UCI()
+	}
+	for _, h := range s.g.ClockHistory()[:ply] {
+		if mc, ok := h.TryGet(); ok {
+			snap.History = append(snap.History, mc)
+		}
+	}
+	if ts := s.timers[ply-s.start]; ts != nil {
+		t := *ts
+		t.Time = s.o.Now()
+		t.Used = 0
+		t.Paused = s.g.TimerPaused()
+		snap.Timer = &t
+	}
+	return clock.GameFromSnapshot(snap, clock.GameOptions{Now: s.o.Now})
+}
+
+func (s *Session) DeclineTakeback(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if t, ok := s.takeback.TryGet(); !ok || t.side == c {
+		return fmt.Errorf("no takeback proposal")
+	}
+	s.takeback = maybe.None[takeback]()
+	return nil
+}
+
+func (s *Session) CanAbort() bool {
+	return !s.g.IsFinished() && s.g.Inner().Len() < s.o.AbortMoves.Get()
+}
+
+// Abort finishes the game without result. It is allowed only in the beginning of the game.
+func (s *Session) Abort(chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if !s.CanAbort() {
+		return fmt.Errorf("too late to abort")
+	}
+	return s.finish(chess.AbortedOutcome())
+}
+
+func (s *Session) Resign(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	return s.finish(chess.MustWinOutcome(chess.VerdictResign, c.Inv()))
+}
+
+// ClaimDraw claims a draw by threefold repetition or by the 50 move rule. The claim can be only
+// made on the side's turn.
+func (s *Session) ClaimDraw(c chess.Color) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if c != s.g.CurSide() {
+		return fmt.Errorf("not %v's turn", c.LongString())
+	}
+	o := s.g.Inner().CalcOutcome()
+	switch o.Verdict() {
+	case chess.VerdictRepeat3, chess.VerdictMoves50:
+		return s.finish(o)
+	default:
+		return fmt.Errorf("no draw to claim")
+	}
+}
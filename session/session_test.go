@@ -0,0 +1,199 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawOffer(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+	c, err := clock.ControlFromString("60")
+	require.NoError(t, err)
+	g := clock.NewGame(chess.NewGame(), maybe.Some(c), clock.GameOptions{
+		Now: func() time.Time { return now },
+	})
+	s := New(g, Options{Now: func() time.Time { return now }})
+
+	require.NoError(t, s.OfferDraw(chess.ColorWhite))
+	assert.Error(t, s.OfferDraw(chess.ColorWhite))
+	assert.Error(t, s.AcceptDraw(chess.ColorWhite))
+	side, ok := s.DrawOffer()
+	assert.True(t, ok)
+	assert.Equal(t, chess.ColorWhite, side)
+
+	// The offer stands after the move of the side which made it.
+	mv, err := chess.MoveFromUCI("e2e4", s.Game().CurBoard())
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	side, ok = s.DrawOffer()
+	assert.True(t, ok)
+	assert.Equal(t, chess.ColorWhite, side)
+
+	// Black ignores the offer and makes a move.
+	mv, err = chess.MoveFromUCI("e7e5", s.Game().CurBoard())
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	require.NoError(t, s.Move(chess.ColorBlack, mv))
+	_, ok = s.DrawOffer()
+	assert.False(t, ok)
+	assert.Error(t, s.AcceptDraw(chess.ColorBlack))
+
+	require.NoError(t, s.OfferDraw(chess.ColorBlack))
+	require.NoError(t, s.DeclineDraw(chess.ColorWhite))
+	require.NoError(t, s.OfferDraw(chess.ColorBlack))
+	require.NoError(t, s.AcceptDraw(chess.ColorWhite))
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAgreement), s.Game().Outcome())
+	assert.Error(t, s.OfferDraw(chess.ColorWhite))
+}
+
+func TestDrawOfferCrossing(t *testing.T) {
+	s := New(clock.NewGame(chess.NewGame(), maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+	require.NoError(t, s.OfferDraw(chess.ColorWhite))
+	require.NoError(t, s.OfferDraw(chess.ColorBlack))
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictDrawAgreement), s.Game().Outcome())
+}
+
+func TestTakeback(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, "2012-09-28T12:34:56Z")
+	require.NoError(t, err)
+	c, err := clock.ControlFromString("60")
+	require.NoError(t, err)
+	g := clock.NewGame(chess.NewGame(), maybe.Some(c), clock.GameOptions{
+		Now: func() time.Time { return now },
+	})
+	s := New(g, Options{Now: func() time.Time { return now }})
+
+	assert.Error(t, s.ProposeTakeback(chess.ColorWhite))
+	for _, m := range []struct {
+		uci string
+		d   time.Duration
+	}{{"e2e4", 5 * time.Second}, {"e7e5", 3 * time.Second}, {"g1f3", 7 * time.Second}} {
+		mv, err := chess.MoveFromUCI(m.uci, s.Game().CurBoard())
+		require.NoError(t, err)
+		now = now.Add(m.d)
+		require.NoError(t, s.Move(s.Game().CurSide(), mv))
+	}
+	now = now.Add(4 * time.Second)
+
+	// It's Black's turn, so both e5 and Nf3 are taken back.
+	require.NoError(t, s.ProposeTakeback(chess.ColorBlack))
+	side, ok := s.Takeback()
+	assert.True(t, ok)
+	assert.Equal(t, chess.ColorBlack, side)
+	assert.Error(t, s.AcceptTakeback(chess.ColorBlack))
+	require.NoError(t, s.AcceptTakeback(chess.ColorWhite))
+
+	g = s.Game()
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", g.CurBoard().FEN())
+	clk, _ := g.Clock()
+	assert.Equal(t, clock.Clock{White: 55 * time.Second, Black: 60 * time.Second, BlackTicking: true}, clk)
+	assert.Len(t, g.ClockHistory(), 1)
+	assert.True(t, g.ClockHistory()[0].IsSome())
+
+	mv, err := chess.MoveFromUCI("c7c5", g.CurBoard())
+	require.NoError(t, err)
+	now = now.Add(2 * time.Second)
+	require.NoError(t, s.Move(chess.ColorBlack, mv))
+	clk, _ = s.Game().Clock()
+	assert.Equal(t, clock.Clock{White: 55 * time.Second, Black: 58 * time.Second, WhiteTicking: true}, clk)
+
+	// White has just moved, so only White's move is taken back.
+	mv, err = chess.MoveFromUCI("g1f3", s.Game().CurBoard())
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	require.NoError(t, s.ProposeTakeback(chess.ColorWhite))
+	require.NoError(t, s.DeclineTakeback(chess.ColorBlack))
+	require.NoError(t, s.ProposeTakeback(chess.ColorWhite))
+	require.NoError(t, s.AcceptTakeback(chess.ColorBlack))
+	assert.Equal(t, 2, s.Game().Inner().Len())
+	assert.Equal(t, chess.ColorWhite, s.Game().CurSide())
+
+	// Moves cancel the proposal.
+	require.NoError(t, s.ProposeTakeback(chess.ColorBlack))
+	mv, err = chess.MoveFromUCI("d2d4", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	_, ok = s.Takeback()
+	assert.False(t, ok)
+}
+
+func TestTakebackStartPos(t *testing.T) {
+	cg, err := chess.GameFromUCIList(chess.InitialBoard(), "e2e4 e7e5")
+	require.NoError(t, err)
+	s := New(clock.NewGame(cg, maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+
+	// The moves made before the session started cannot be taken back.
+	assert.EqualError(t, s.ProposeTakeback(chess.ColorBlack), "no move to take back")
+	mv, err := chess.MoveFromUCI("g1f3", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	assert.EqualError(t, s.ProposeTakeback(chess.ColorBlack), "no move to take back")
+	require.NoError(t, s.ProposeTakeback(chess.ColorWhite))
+	require.NoError(t, s.AcceptTakeback(chess.ColorBlack))
+	assert.Equal(t, cg.CurBoard().FEN(), s.Game().CurBoard().FEN())
+	assert.False(t, s.Game().HasTimer())
+}
+
+func TestAbortAndResign(t *testing.T) {
+	s := New(clock.NewGame(chess.NewGame(), maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+	assert.True(t, s.CanAbort())
+	mv, err := chess.MoveFromUCI("e2e4", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	assert.True(t, s.CanAbort())
+	mv, err = chess.MoveFromUCI("e7e5", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorBlack, mv))
+	assert.False(t, s.CanAbort())
+	assert.EqualError(t, s.Abort(chess.ColorWhite), "too late to abort")
+	require.NoError(t, s.Resign(chess.ColorWhite))
+	assert.Equal(t, chess.MustWinOutcome(chess.VerdictResign, chess.ColorBlack), s.Game().Outcome())
+
+	s = New(clock.NewGame(chess.NewGame(), maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+	mv, err = chess.MoveFromUCI("e2e4", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	require.NoError(t, s.Abort(chess.ColorBlack))
+	assert.Equal(t, chess.AbortedOutcome(), s.Game().Outcome())
+	assert.True(t, s.Game().IsFinished())
+	assert.Equal(t, chess.StatusRunning, s.Game().Outcome().Status())
+	assert.Equal(t, "game aborted", s.Game().Outcome().String())
+
+	s = New(clock.NewGame(chess.NewGame(), maybe.None[clock.Control](), clock.GameOptions{}), Options{
+		AbortMoves: maybe.Some(0),
+	})
+	assert.False(t, s.CanAbort())
+	assert.EqualError(t, s.Abort(chess.ColorWhite), "too late to abort")
+}
+
+func TestClaimDraw(t *testing.T) {
+	s := New(clock.NewGame(chess.NewGame(), maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+	assert.EqualError(t, s.ClaimDraw(chess.ColorWhite), "no draw to claim")
+	for _, uci := range []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"} {
+		mv, err := chess.MoveFromUCI(uci, s.Game().CurBoard())
+		require.NoError(t, err)
+		require.NoError(t, s.Move(s.Game().CurSide(), mv))
+	}
+	assert.False(t, s.Game().IsFinished())
+	assert.EqualError(t, s.ClaimDraw(chess.ColorBlack), "not black's turn")
+	require.NoError(t, s.ClaimDraw(chess.ColorWhite))
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictRepeat3), s.Game().Outcome())
+
+	b, err := chess.BoardFromFEN("7k/8/8/8/8/8/8/R6K w - - 99 80")
+	require.NoError(t, err)
+	s = New(clock.NewGame(chess.NewGameWithPosition(b), maybe.None[clock.Control](), clock.GameOptions{}), Options{})
+	mv, err := chess.MoveFromUCI("a1a2", s.Game().CurBoard())
+	require.NoError(t, err)
+	require.NoError(t, s.Move(chess.ColorWhite, mv))
+	require.NoError(t, s.ClaimDraw(chess.ColorBlack))
+	assert.Equal(t, chess.MustDrawOutcome(chess.VerdictMoves50), s.Game().Outcome())
+}
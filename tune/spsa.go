@@ -0,0 +1,380 @@
+// Package tune implements SPSA (Simultaneous Perturbation Stochastic Approximation) tuning of the
+// engine parameters exposed as UCI spin options.
+//
+// On each iteration, all the parameters are perturbed simultaneously in random directions, and a
+// pair of games is played between the engine with the parameters shifted forward and the engine
+// with the parameters shifted backward. Then, the parameters are moved towards the winner.
+package tune
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/play"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+type Param struct {
+	// Name of the spin option.
+	Name string
+
+	// Initial value. If None, then the current value of the option is used.
+	Start maybe.Maybe[float64]
+
+	// Bounds of the parameter. If both are zero, then the bounds of the option are used.
+	Min float64
+	Max float64
+
+	// Perturbation size at the end of the tuning.
+	CEnd float64
+
+	// Learning rate at the end of the tuning. The parameter moves by at most about REnd*CEnd^2 on
+	// the last iteration.
+	REnd float64
+}
+
+type Options struct {
+	Params []Param
+
+	// Total number of iterations. Each iteration plays Pairs game pairs.
+	Iterations int
+
+	// Number of game pairs per iteration.
+	//
+	// Zero means default.
+	Pairs int
+
+	// Exponents and stability constant for the learning rate and the perturbation size.
+	//
+	// Zero means default.
+	Alpha     float64
+	Gamma     float64
+	Stability float64
+
+	// Creates a new instance of the engine. Two instances are created. Options other than the
+	// tuned ones must be set by this function.
+	NewEngine func(ctx context.Context) (*uci.Engine, error)
+
+	// Time control for the games. If None, then GoOptions must limit the search.
+	Control maybe.Maybe[clock.Control]
+
+	// Additional search options, like depth or nodes limit.
+	GoOptions uci.GoOptions
+
+	// Starting positions. A random one is chosen for each game pair. If empty, then the games start
+	// from the initial position.
+	Openings []*chess.Game
+
+	Adjudication maybe.Maybe[play.AdjudicationRules]
+
+	// Path to the checkpoint file. If the file exists, then the tuning is resumed from it. If
+	// empty, then no checkpoints are made.
+	Checkpoint string
+
+	// Save the checkpoint every CheckpointEvery iterations.
+	//
+	// Zero means default.
+	CheckpointEvery int
+
+	// Called after each iteration.
+	OnIteration func(s State)
+
+	// Used to choose the seed when the tuning starts from scratch. The seed is saved in the
+	// checkpoint, see State.
+	Rand *rand.Rand
+}
+
+func (o Options) Clone() Options {
+	o.Params = slices.Clone(o.Params)
+	o.GoOptions = o.GoOptions.Clone()
+	o.Openings = slices.Clone(o.Openings)
+	return o
+}
+
+func (o *Options) FillDefaults() {
+	if o.Pairs == 0 {
+		o.Pairs = 1
+	}
+	if o.Alpha == 0 {
+		o.Alpha = 0.602
+	}
+	if o.Gamma == 0 {
+		o.Gamma = 0.101
+	}
+	if o.Stability == 0 {
+		o.Stability = 0.1 * float64(o.Iterations)
+	}
+	if o.CheckpointEvery == 0 {
+		o.CheckpointEvery = 1
+	}
+	if o.Rand == nil {
+		o.Rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+}
+
+func (o *Options) Validate() error {
+	if len(o.Params) == 0 {
+		return fmt.Errorf("no params")
+	}
+	if o.Iterations <= 0 {
+		return fmt.Errorf("non-positive iterations")
+	}
+	if o.NewEngine == nil {
+		return fmt.Errorf("no engine constructor")
+	}
+	seen := make(map[string]struct{})
+	for _, p := range o.Params {
+		if _, ok := seen[p.Name]; ok {
+			return fmt.Errorf("duplicate param %q", p.Name)
+		}
+		seen[p.Name] = struct{}{}
+		if p.CEnd <= 0 || p.REnd <= 0 {
+			return fmt.Errorf("param %q: non-positive coefficients", p.Name)
+		}
+		if p.Min > p.Max {
+			return fmt.Errorf("param %q: bad bounds", p.Name)
+		}
+	}
+	return nil
+}
+
+type Tuner struct {
+	o      Options
+	params []Param
+	s      State
+	e      [2]*uci.Engine
+}
+
+func New(o Options) (*Tuner, error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return &Tuner{o: o, params: o.Params}, nil
+}
+
+func (t *Tuner) State() State {
+	return t.s.Clone()
+}
+
+func (t *Tuner) engine(ctx context.Context, i int) (*uci.Engine, error) {
+	if t.e[i] != nil && !t.e[i].Terminated() {
+		return t.e[i], nil
+	}
+	if t.e[i] != nil {
+		t.e[i].Close()
+	}
+	e, err := t.o.NewEngine(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create engine: %w", err)
+	}
+	t.e[i] = e
+	return e, nil
+}
+
+func (t *Tuner) close() {
+	for i, e := range t.e {
+		if e != nil {
+			e.Close()
+			t.e[i] = nil
+		}
+	}
+}
+
+// Fills the bounds and the initial values from the engine options.
+func (t *Tuner) init(ctx context.Context) error {
+	e, err := t.engine(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for i := range t.params {
+		p := &t.params[i]
+		opt, ok := e.GetOpt(p.Name).(*uci.OptionSpin)
+		if !ok {
+			return fmt.Errorf("param %q: no such spin option", p.Name)
+		}
+		if p.Min == 0 && p.Max == 0 {
+			p.Min = float64(opt.MinValue())
+			p.Max = float64(opt.MaxValue())
+		}
+		if float64(opt.MinValue()) > p.Min || float64(opt.MaxValue()) < p.Max {
+			return fmt.Errorf("param %q: bounds are wider than the option bounds", p.Name)
+		}
+		if _, ok := t.s.Values[p.Name]; !ok {
+			t.s.Values[p.Name] = t.clamp(p, p.Start.GetOr(float64(opt.IntValue())))
+		}
+	}
+	return nil
+}
+
+func (t *Tuner) clamp(p *Param, v float64) float64 {
+	return min(max(v, p.Min), p.Max)
+}
+
+func (t *Tuner) coeffs(p *Param, k int) (c, r float64) {
+	n := float64(t.o.Iterations)
+	cStart := p.CEnd * math.Pow(n, t.o.Gamma)
+	aEnd := p.REnd * p.CEnd * p.CEnd
+	aStart := aEnd * math.Pow(t.o.Stability+n, t.o.Alpha)
+	c = cStart / math.Pow(float64(k), t.o.Gamma)
+	a := aStart / math.Pow(t.o.Stability+float64(k), t.o.Alpha)
+	return c, a / (c * c)
+}
+
+func (t *Tuner) configure(ctx context.Context, e *uci.Engine, values map[string]float64) error {
+	for _, p := range t.params {
+		v := int64(math.Round(values[p.Name]))
+		if err := e.SetOption(ctx, p.Name, uci.OptValueInt(v)); err != nil {
+			return fmt.Errorf("set option %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Tuner) playGame(
+	ctx context.Context,
+	opening *chess.Game,
+	white, black *uci.Engine,
+) (chess.Outcome, error) {
+	game := clock.NewGame(opening, t.o.Control, clock.GameOptions{
+		OutcomeFilter: maybe.Some(chess.VerdictFilterRelaxed),
+	})
+	var players [chess.ColorMax]play.Player
+	for c, e := range [chess.ColorMax]*uci.Engine{white, black} {
+		if err := e.UCINewGame(ctx, true); err != nil {
+			return chess.Outcome{}, fmt.Errorf("ucinewgame: %w", err)
+		}
+		players[c] = play.NewEnginePlayer(e, play.EnginePlayerOptions{GoOptions: t.o.GoOptions})
+	}
+	res, err := play.PlayGame(ctx, game, players, play.Options{Adjudication: t.o.Adjudication})
+	if err != nil {
+		return chess.Outcome{}, err
+	}
+	return res.Outcome, nil
+}
+
+func scoreFor(o chess.Outcome, c chess.Color) float64 {
+	switch o.Status() {
+	case chess.StatusDraw:
+		return 0.5
+	case chess.StatusWin(c):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Plays a game pair between the engines with the plus and minus values and returns the score of
+// the plus engine minus the score of the minus engine.
+func (t *Tuner) playPair(
+	ctx context.Context,
+	rnd *rand.Rand,
+	plus, minus map[string]float64,
+) (float64, error) {
+	ep, err := t.engine(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	em, err := t.engine(ctx, 1)
+	if err != nil {
+		return 0, err
+	}
+	if err := t.configure(ctx, ep, plus); err != nil {
+		return 0, fmt.Errorf("configure plus engine: %w", err)
+	}
+	if err := t.configure(ctx, em, minus); err != nil {
+		return 0, fmt.Errorf("configure minus engine: %w", err)
+	}
+
+	opening := chess.NewGame()
+	if len(t.o.Openings) != 0 {
+		opening = t.o.Openings[rnd.IntN(len(t.o.Openings))]
+	}
+	var result float64
+	for c := range chess.ColorMax {
+		white, black := ep, em
+		if c == chess.ColorBlack {
+			white, black = em, ep
+		}
+		o, err := t.playGame(ctx, opening, white, black)
+		if err != nil {
+			return 0, fmt.Errorf("play game: %w", err)
+		}
+		t.s.addGame(o, c)
+		result += 2*scoreFor(o, c) - 1
+	}
+	return result, nil
+}
+
+func (t *Tuner) iterate(ctx context.Context) error {
+	k := t.s.Iteration + 1
+	rnd := rand.New(rand.NewPCG(t.s.Seed, uint64(k)))
+	for range t.o.Pairs {
+		plus := make(map[string]float64, len(t.params))
+		minus := make(map[string]float64, len(t.params))
+		deltas := make([]float64, len(t.params))
+		for i := range t.params {
+			p := &t.params[i]
+			c, _ := t.coeffs(p, k)
+			deltas[i] = float64(2*rnd.IntN(2) - 1)
+			v := t.s.Values[p.Name]
+			plus[p.Name] = t.clamp(p, v+c*deltas[i])
+			minus[p.Name] = t.clamp(p, v-c*deltas[i])
+		}
+		result, err := t.playPair(ctx, rnd, plus, minus)
+		if err != nil {
+			return err
+		}
+		for i := range t.params {
+			p := &t.params[i]
+			c, r := t.coeffs(p, k)
+			t.s.Values[p.Name] = t.clamp(p, t.s.Values[p.Name]+r*c*result*deltas[i])
+		}
+	}
+	t.s.Iteration = k
+	return nil
+}
+
+// Run performs the tuning and returns the final state. If ctx is cancelled, then the checkpoint is
+// left at the last finished iteration, so the tuning can be resumed later.
+func (t *Tuner) Run(ctx context.Context) (State, error) {
+	defer t.close()
+
+	t.s = newState()
+	t.s.Seed = t.o.Rand.Uint64()
+	if t.o.Checkpoint != "" {
+		s, ok, err := LoadCheckpoint(t.o.Checkpoint)
+		if err != nil {
+			return State{}, fmt.Errorf("load checkpoint: %w", err)
+		}
+		if ok {
+			t.s = s
+		}
+	}
+	if err := t.init(ctx); err != nil {
+		return t.State(), fmt.Errorf("init: %w", err)
+	}
+
+	for t.s.Iteration < t.o.Iterations {
+		if err := t.iterate(ctx); err != nil {
+			return t.State(), fmt.Errorf("iteration %v: %w", t.s.Iteration+1, err)
+		}
+		if t.o.OnIteration != nil {
+			t.o.OnIteration(t.State())
+		}
+		if t.o.Checkpoint != "" &&
+			(t.s.Iteration%t.o.CheckpointEvery == 0 || t.s.Iteration == t.o.Iterations) {
+			if err := SaveCheckpoint(t.o.Checkpoint, t.s); err != nil {
+				return t.State(), fmt.Errorf("save checkpoint: %w", err)
+			}
+		}
+	}
+	return t.State(), nil
+}
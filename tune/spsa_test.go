@@ -0,0 +1,147 @@
+package tune
+
+import (
+	"context"
+	"math/rand/v2"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/play"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The engine is confident when its strength is above 50, and desperate otherwise. So, with
+// resign adjudication, the stronger engine wins if the strengths are on the different sides of
+// 50.
+func newEngine(ctx context.Context) (*uci.Engine, error) {
+	var fe *ucitest.Engine
+	fe = ucitest.NewEngine(ucitest.EngineOptions{
+		Options: []string{"option name Strength type spin default 50 min 0 max 100"},
+		Eval: func(*chess.Board) uci.Score {
+			v, _ := fe.Option("Strength")
+			s, _ := strconv.ParseInt(v, 10, 32)
+			return uci.ScoreCentipawns(int32(s-50) * 100)
+		},
+	})
+	e := uci.NewEngine(ctx, fe, nil, uci.EngineOptions{})
+	if err := e.WaitInitialized(ctx); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func TestSPSA(t *testing.T) {
+	ctx := context.Background()
+	checkpoint := filepath.Join(t.TempDir(), "spsa.json")
+
+	opts := Options{
+		Params: []Param{
+			{Name: "Strength", CEnd: 2, REnd: 0.5},
+		},
+		Iterations: 4,
+		NewEngine:  newEngine,
+		GoOptions:  uci.GoOptions{Depth: maybe.Some[int64](1)},
+		Adjudication: maybe.Some(play.AdjudicationRules{
+			Resign:   maybe.Some(play.ResignRule{MoveCount: 1, Score: 100}),
+			MaxMoves: maybe.Some(2),
+		}),
+		Checkpoint: checkpoint,
+		Rand:       rand.New(rand.NewPCG(1, 2)),
+	}
+	tuner, err := New(opts)
+	require.NoError(t, err)
+	s, err := tuner.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, s.Iteration)
+	assert.Equal(t, 8, s.Wins+s.Draws+s.Losses)
+	assert.Greater(t, s.Values["Strength"], 50.0)
+	assert.LessOrEqual(t, s.Values["Strength"], 100.0)
+
+	saved, ok, err := LoadCheckpoint(checkpoint)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, s, saved)
+
+	// Resume from the checkpoint.
+	opts.Iterations = 6
+	var iterations []int
+	opts.OnIteration = func(s State) { iterations = append(iterations, s.Iteration) }
+	tuner, err = New(opts)
+	require.NoError(t, err)
+	s, err = tuner.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 6}, iterations)
+	assert.Equal(t, 12, s.Wins+s.Draws+s.Losses)
+
+	opts.Params = []Param{{Name: "NoSuchOption", CEnd: 1, REnd: 1}}
+	opts.Checkpoint = ""
+	tuner, err = New(opts)
+	require.NoError(t, err)
+	_, err = tuner.Run(ctx)
+	assert.Error(t, err)
+}
+
+func TestSPSAResume(t *testing.T) {
+	opts := Options{
+		Params:     []Param{{Name: "Strength", CEnd: 2, REnd: 0.5}},
+		Iterations: 6,
+		NewEngine:  newEngine,
+		GoOptions:  uci.GoOptions{Depth: maybe.Some[int64](1)},
+		Adjudication: maybe.Some(play.AdjudicationRules{
+			Resign:   maybe.Some(play.ResignRule{MoveCount: 1, Score: 100}),
+			MaxMoves: maybe.Some(2),
+		}),
+		Rand: rand.New(rand.NewPCG(1, 2)),
+	}
+	tuner, err := New(opts)
+	require.NoError(t, err)
+	straight, err := tuner.Run(context.Background())
+	require.NoError(t, err)
+
+	// Interrupt the tuning after the third iteration and resume it. The resumed run must make the
+	// same choices as the uninterrupted one.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts.Checkpoint = filepath.Join(t.TempDir(), "spsa.json")
+	opts.Rand = rand.New(rand.NewPCG(1, 2))
+	opts.OnIteration = func(s State) {
+		if s.Iteration == 3 {
+			cancel()
+		}
+	}
+	tuner, err = New(opts)
+	require.NoError(t, err)
+	_, err = tuner.Run(ctx)
+	require.Error(t, err)
+
+	opts.Rand = rand.New(rand.NewPCG(3, 4))
+	opts.OnIteration = nil
+	tuner, err = New(opts)
+	require.NoError(t, err)
+	resumed, err := tuner.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, straight, resumed)
+}
+
+func TestCoeffs(t *testing.T) {
+	tuner, err := New(Options{
+		Params:     []Param{{Name: "X", CEnd: 4, REnd: 0.002}},
+		Iterations: 100,
+		NewEngine:  newEngine,
+	})
+	require.NoError(t, err)
+	p := &tuner.params[0]
+	c, r := tuner.coeffs(p, 100)
+	assert.InDelta(t, 4.0, c, 1e-9)
+	assert.InDelta(t, 0.002, r, 1e-9)
+	c1, r1 := tuner.coeffs(p, 1)
+	assert.Greater(t, c1, c)
+	assert.Greater(t, r1*c1*c1, r*c*c)
+}
@@ -0,0 +1,106 @@
+package tune
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/alex65536/go-chess/chess"
+)
+
+// State is the tuning progress. It is saved into the checkpoint file as JSON.
+type State struct {
+	Iteration int                `json:"iteration"`
+	Values    map[string]float64 `json:"values"`
+
+	// Seed for the random numbers. The generator for each iteration is seeded with it and the
+	// iteration number, so the resumed tuning makes the same choices as the uninterrupted one.
+	Seed uint64 `json:"seed"`
+
+	// Game results from the point of view of the engine with plus perturbation.
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+}
+
+func newState() State {
+	return State{Values: make(map[string]float64)}
+}
+
+func (s State) Clone() State {
+	s.Values = maps.Clone(s.Values)
+	return s
+}
+
+// IntValues returns the current parameter values rounded to integers, as they are passed to the
+// engine.
+func (s *State) IntValues() map[string]int64 {
+	res := make(map[string]int64, len(s.Values))
+	for k, v := range s.Values {
+		res[k] = int64(math.Round(v))
+	}
+	return res
+}
+
+func (s *State) addGame(o chess.Outcome, plusSide chess.Color) {
+	switch scoreFor(o, plusSide) {
+	case 1:
+		s.Wins++
+	case 0:
+		s.Losses++
+	default:
+		s.Draws++
+	}
+}
+
+// LoadCheckpoint loads the state from the file. If the file doesn't exist, then false is returned.
+func LoadCheckpoint(path string) (State, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return State{}, false, nil
+		}
+		return State{}, false, fmt.Errorf("read: %w", err)
+	}
+	s := newState()
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}, false, fmt.Errorf("decode: %w", err)
+	}
+	if s.Values == nil {
+		s.Values = make(map[string]float64)
+	}
+	return s, true, nil
+}
+
+// SaveCheckpoint saves the state into the file. The file is replaced atomically, so the previous
+// checkpoint survives if the process is interrupted while saving.
+func SaveCheckpoint(path string, s State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+// This package checks whether UCI engines follow the protocol. It runs a battery of scenarios against
+// the engine and reports the violations found.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+type ProcessFactory func() (uci.Process, error)
+
+func CmdFactory(name string, args ...string) ProcessFactory {
+	return func() (uci.Process, error) {
+		return uci.NewCmdProcess(exec.Command(name, args...))
+	}
+}
+
+type Options struct {
+	EngineOptions uci.EngineOptions
+	Logger        uci.Logger
+
+	// Maximum time to wait for "readyok" after "isready".
+	//
+	// Zero means default.
+	ReadyTimeout time.Duration
+
+	// Maximum time to wait for "bestmove" after "stop".
+	//
+	// Zero means default.
+	StopTimeout time.Duration
+
+	// Maximum time to wait for "bestmove" in searches limited by depth or nodes.
+	//
+	// Zero means default.
+	SearchTimeout time.Duration
+
+	// Maximum time to wait until the engine exits after "quit".
+	//
+	// Zero means default.
+	QuitTimeout time.Duration
+
+	// How much the engine may exceed the allocated time when it is limited by time.
+	//
+	// Zero means default.
+	TimeSlack time.Duration
+
+	// How many nodes the engine may search above the requested node limit.
+	//
+	// Zero means default.
+	NodesSlack int64
+
+	// Limits used in the corresponding scenarios.
+	//
+	// Zero means default.
+	Depth    int64
+	Nodes    int64
+	Movetime time.Duration
+
+	// Scenarios to run. If empty, DefaultScenarios() are used.
+	Scenarios []Scenario
+}
+
+func (o Options) Clone() Options {
+	o.Scenarios = slices.Clone(o.Scenarios)
+	return o
+}
+
+func (o *Options) FillDefaults() {
+	if o.Logger == nil {
+		o.Logger = uci.NewNullLogger()
+	}
+	if o.ReadyTimeout == 0 {
+		o.ReadyTimeout = 1 * time.Second
+	}
+	if o.StopTimeout == 0 {
+		o.StopTimeout = 1 * time.Second
+	}
+	if o.SearchTimeout == 0 {
+		o.SearchTimeout = 30 * time.Second
+	}
+	if o.QuitTimeout == 0 {
+		o.QuitTimeout = 1 * time.Second
+	}
+	if o.TimeSlack == 0 {
+		o.TimeSlack = 100 * time.Millisecond
+	}
+	if o.NodesSlack == 0 {
+		o.NodesSlack = 1000
+	}
+	if o.Depth == 0 {
+		o.Depth = 4
+	}
+	if o.Nodes == 0 {
+		o.Nodes = 10000
+	}
+	if o.Movetime == 0 {
+		o.Movetime = 500 * time.Millisecond
+	}
+	if len(o.Scenarios) == 0 {
+		o.Scenarios = DefaultScenarios()
+	}
+}
+
+type Violation struct {
+	Scenario string
+	Msg      string
+}
+
+func (v Violation) String() string {
+	return v.Scenario + ": " + v.Msg
+}
+
+type ScenarioResult struct {
+	Name       string
+	Skipped    string
+	Err        error
+	Violations []Violation
+	Transcript []Line
+}
+
+func (r *ScenarioResult) OK() bool {
+	return r.Err == nil && len(r.Violations) == 0
+}
+
+type Report struct {
+	Engine  uci.EngineInfo
+	Results []ScenarioResult
+}
+
+func (r *Report) Violations() []Violation {
+	var res []Violation
+	for _, s := range r.Results {
+		res = append(res, s.Violations...)
+	}
+	return res
+}
+
+func (r *Report) OK() bool {
+	for _, s := range r.Results {
+		if !s.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "engine: %v by %v\n", r.Engine.Name, r.Engine.Author)
+	for _, s := range r.Results {
+		switch {
+		case s.Skipped != "":
+			_, _ = fmt.Fprintf(&b, "[SKIP] %v: %v\n", s.Name, s.Skipped)
+		case s.OK():
+			_, _ = fmt.Fprintf(&b, "[ OK ] %v\n", s.Name)
+		default:
+			_, _ = fmt.Fprintf(&b, "[FAIL] %v\n", s.Name)
+			if s.Err != nil {
+				_, _ = fmt.Fprintf(&b, "  error: %v\n", s.Err)
+			}
+			for _, v := range s.Violations {
+				_, _ = fmt.Fprintf(&b, "  %v\n", v.Msg)
+			}
+		}
+	}
+	return b.String()
+}
+
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, env *Env) error
+}
+
+type Env struct {
+	o   *Options
+	e   *uci.Engine
+	rec *recorder
+	res *ScenarioResult
+}
+
+func (e *Env) Engine() *uci.Engine { return e.e }
+func (e *Env) Options() *Options   { return e.o }
+func (e *Env) Transcript() []Line  { return e.rec.Lines() }
+func (e *Env) Skip(reason string)  { e.res.Skipped = reason }
+func (e *Env) Violatef(msg string, args ...any) {
+	e.res.Violations = append(e.res.Violations, Violation{
+		Scenario: e.res.Name,
+		Msg:      fmt.Sprintf(msg, args...),
+	})
+}
+
+func Run(ctx context.Context, f ProcessFactory, o Options) *Report {
+	o = o.Clone()
+	o.FillDefaults()
+	rep := &Report{}
+	for _, s := range o.Scenarios {
+		res := runScenario(ctx, f, &o, s)
+		if res.info.Name != "" && rep.Engine.Name == "" {
+			rep.Engine = res.info
+		}
+		rep.Results = append(rep.Results, res.ScenarioResult)
+	}
+	return rep
+}
+
+type scenarioResultExt struct {
+	ScenarioResult
+	info uci.EngineInfo
+}
+
+func runScenario(ctx context.Context, f ProcessFactory, o *Options, s Scenario) (res scenarioResultExt) {
+	res = scenarioResultExt{ScenarioResult: ScenarioResult{Name: s.Name}}
+
+	p, err := f()
+	if err != nil {
+		res.Err = fmt.Errorf("create process: %w", err)
+		return res
+	}
+	rec := newRecorder(p)
+	engineCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	e := uci.NewEngine(engineCtx, rec, o.Logger, o.EngineOptions)
+	defer e.Close()
+
+	env := &Env{o: o, e: e, rec: rec, res: &res.ScenarioResult}
+	defer func() {
+		res.Transcript = rec.Lines()
+		checkInfoLines(env, res.Transcript)
+	}()
+
+	if err := e.WaitInitialized(ctx); err != nil {
+		res.Err = fmt.Errorf("wait initialized: %w", err)
+		return res
+	}
+	res.info, _ = e.Info()
+
+	if err := s.Run(ctx, env); err != nil {
+		res.Err = err
+		return res
+	}
+
+	quitCtx, quitCancel := context.WithTimeout(ctx, o.QuitTimeout)
+	defer quitCancel()
+	if err := e.Quit(quitCtx, true); err != nil {
+		env.Violatef("engine does not quit in %v", o.QuitTimeout)
+	}
+	return res
+}
+
+type collectLogger struct {
+	msgs []string
+}
+
+func (l *collectLogger) Printf(msg string, args ...any) {
+	l.msgs = append(l.msgs, fmt.Sprintf(msg, args...))
+}
+
+func checkInfoLines(env *Env, lines []Line) {
+	for _, ln := range lines {
+		if ln.Dir != DirRecv || !strings.HasPrefix(ln.Text, "info") {
+			continue
+		}
+		var l collectLogger
+		if _, err := uci.ParseInfo(ln.Text, &l); err != nil {
+			env.Violatef("unparsable info line %q: %v", ln.Text, err)
+			continue
+		}
+		if len(l.msgs) != 0 {
+			env.Violatef("unparsable info line %q: %v", ln.Text, strings.Join(l.msgs, "; "))
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package conformance
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return Options{
+		ReadyTimeout:  50 * time.Millisecond,
+		StopTimeout:   100 * time.Millisecond,
+		QuitTimeout:   100 * time.Millisecond,
+		SearchTimeout: 1 * time.Second,
+		TimeSlack:     50 * time.Millisecond,
+		Movetime:      50 * time.Millisecond,
+	}
+}
+
+func factory(o ucitest.EngineOptions) ProcessFactory {
+	return func() (uci.Process, error) {
+		return ucitest.NewProcess(o), nil
+	}
+}
+
+func scenario(name string) Scenario {
+	for _, s := range DefaultScenarios() {
+		if s.Name == name {
+			return s
+		}
+	}
+	panic("no such scenario")
+}
+
+var ponderOpt = "option name Ponder type check default false"
+
+func TestGoodEngine(t *testing.T) {
+	rep := Run(context.Background(), factory(ucitest.EngineOptions{
+		Name:    "Good",
+		Options: []string{ponderOpt, "option name Hash type spin default 16 min 1 max 1024"},
+	}), testOptions())
+	assert.True(t, rep.OK(), rep.String())
+	assert.Equal(t, "Good", rep.Engine.Name)
+	assert.Len(t, rep.Results, len(DefaultScenarios()))
+}
+
+func TestViolations(t *testing.T) {
+	preferE4 := func(_ *chess.Board, candidates []chess.Move) chess.Move {
+		for _, m := range candidates {
+			if m.UCI() == "e2e4" {
+				return m
+			}
+		}
+		return candidates[0]
+	}
+
+	for _, tc := range []struct {
+		name     string
+		scenario string
+		o        ucitest.EngineOptions
+		msg      string
+	}{
+		{
+			name:     "infoBeforeUCIOk",
+			scenario: "handshake",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if line == "uci" {
+					e.Reply("info string hello")
+					e.Process(line)
+					return true
+				}
+				return false
+			}},
+			msg: "info before",
+		},
+		{
+			name:     "badOption",
+			scenario: "handshake",
+			o:        ucitest.EngineOptions{Options: []string{"option name Hash type spin default 0 min 1 max 16"}},
+			msg:      "out of range",
+		},
+		{
+			name:     "lateReadyOk",
+			scenario: "isready",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if line == "isready" {
+					go func() {
+						if e.Sleep(200 * time.Millisecond) {
+							e.Reply("readyok")
+						}
+					}()
+					return true
+				}
+				return false
+			}},
+			msg: "late \"readyok\"",
+		},
+		{
+			name:     "illegalBestMove",
+			scenario: "bestmove",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if strings.HasPrefix(line, "go") {
+					e.Reply("bestmove a1a8")
+					return true
+				}
+				return false
+			}},
+			msg: "illegal \"bestmove\"",
+		},
+		{
+			name:     "ignoreStop",
+			scenario: "stop",
+			o: ucitest.EngineOptions{Hook: func(_ *ucitest.Engine, line string) bool {
+				return line == "stop"
+			}},
+			msg: "ignores \"stop\"",
+		},
+		{
+			name:     "ponderMisuse",
+			scenario: "ponder",
+			o: ucitest.EngineOptions{
+				Options: []string{ponderOpt},
+				Hook: func(e *ucitest.Engine, line string) bool {
+					if strings.HasPrefix(line, "go ponder") {
+						e.Process(strings.Replace(line, "ponder", "", 1))
+						return true
+					}
+					return false
+				},
+			},
+			msg: "during ponder",
+		},
+		{
+			name:     "searchMoves",
+			scenario: "searchmoves",
+			o: ucitest.EngineOptions{
+				Choose: preferE4,
+				Hook: func(e *ucitest.Engine, line string) bool {
+					if before, _, ok := strings.Cut(line, " searchmoves"); ok {
+						e.Process(before)
+						return true
+					}
+					return false
+				},
+			},
+			msg: "not in searchmoves",
+		},
+		{
+			name:     "depth",
+			scenario: "depth",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if strings.HasPrefix(line, "go") {
+					e.Reply("info depth 99")
+				}
+				return false
+			}},
+			msg: "exceeds the limit",
+		},
+		{
+			name:     "nodes",
+			scenario: "nodes",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if strings.HasPrefix(line, "go") {
+					e.Reply("info nodes 1000000")
+				}
+				return false
+			}},
+			msg: "exceed the limit",
+		},
+		{
+			name:     "movetime",
+			scenario: "movetime",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if strings.HasPrefix(line, "go") {
+					e.Sleep(300 * time.Millisecond)
+				}
+				return false
+			}},
+			msg: "within movetime",
+		},
+		{
+			name:     "badInfo",
+			scenario: "depth",
+			o: ucitest.EngineOptions{Hook: func(e *ucitest.Engine, line string) bool {
+				if strings.HasPrefix(line, "go") {
+					e.Reply("info depth x")
+				}
+				return false
+			}},
+			msg: "unparsable info",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := testOptions()
+			o.Scenarios = []Scenario{scenario(tc.scenario)}
+			rep := Run(context.Background(), factory(tc.o), o)
+			require.False(t, rep.OK())
+			found := false
+			for _, v := range rep.Violations() {
+				if strings.Contains(v.Msg, tc.msg) {
+					found = true
+				}
+			}
+			assert.True(t, found, rep.String())
+		})
+	}
+}
+
+func TestCheckOptionLine(t *testing.T) {
+	assert.NoError(t, checkOptionLine("option name Hash type spin default 16 min 1 max 1024"))
+	assert.NoError(t, checkOptionLine("option name Style type combo default Solid var Solid var Risky"))
+	assert.NoError(t, checkOptionLine("option name Clear Hash type button"))
+	assert.NoError(t, checkOptionLine("option name Book File type string default <empty>"))
+	assert.Error(t, checkOptionLine("option name Hash type spin default 16"))
+	assert.Error(t, checkOptionLine("option name Style type combo default Wild var Solid var Risky"))
+	assert.Error(t, checkOptionLine("option name Ponder type check default yes"))
+	assert.Error(t, checkOptionLine("option name Foo type slider default 1"))
+}
@@ -0,0 +1,127 @@
+package conformance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var optionKeywords = map[string]struct{}{
+	"name":    {},
+	"type":    {},
+	"default": {},
+	"min":     {},
+	"max":     {},
+	"var":     {},
+}
+
+type rawOption struct {
+	name    string
+	typ     string
+	def     *string
+	minV    *string
+	maxV    *string
+	choices []string
+}
+
+func parseRawOption(line string) rawOption {
+	var o rawOption
+	fields := strings.Fields(line)
+	if len(fields) != 0 && fields[0] == "option" {
+		fields = fields[1:]
+	}
+	for i := 0; i < len(fields); {
+		kw := fields[i]
+		i++
+		j := i
+		for j < len(fields) {
+			if _, ok := optionKeywords[fields[j]]; ok {
+				break
+			}
+			j++
+		}
+		val := strings.Join(fields[i:j], " ")
+		i = j
+		switch kw {
+		case "name":
+			o.name = val
+		case "type":
+			o.typ = val
+		case "default":
+			o.def = &val
+		case "min":
+			o.minV = &val
+		case "max":
+			o.maxV = &val
+		case "var":
+			o.choices = append(o.choices, val)
+		}
+	}
+	return o
+}
+
+func checkOptionLine(line string) error {
+	o := parseRawOption(line)
+	if o.name == "" {
+		return fmt.Errorf("no name")
+	}
+	if o.typ == "" {
+		return fmt.Errorf("option %q: no type", o.name)
+	}
+	switch o.typ {
+	case "check":
+		if o.def == nil {
+			return fmt.Errorf("option %q: no default", o.name)
+		}
+		if *o.def != "true" && *o.def != "false" {
+			return fmt.Errorf("option %q: bad default %q for check", o.name, *o.def)
+		}
+	case "spin":
+		if o.def == nil || o.minV == nil || o.maxV == nil {
+			return fmt.Errorf("option %q: spin must have default, min and max", o.name)
+		}
+		def, err := strconv.ParseInt(*o.def, 10, 64)
+		if err != nil {
+			return fmt.Errorf("option %q: bad default %q", o.name, *o.def)
+		}
+		minV, err := strconv.ParseInt(*o.minV, 10, 64)
+		if err != nil {
+			return fmt.Errorf("option %q: bad min %q", o.name, *o.minV)
+		}
+		maxV, err := strconv.ParseInt(*o.maxV, 10, 64)
+		if err != nil {
+			return fmt.Errorf("option %q: bad max %q", o.name, *o.maxV)
+		}
+		if minV > maxV {
+			return fmt.Errorf("option %q: empty range [%v; %v]", o.name, minV, maxV)
+		}
+		if def < minV || def > maxV {
+			return fmt.Errorf("option %q: default %v out of range [%v; %v]", o.name, def, minV, maxV)
+		}
+	case "combo":
+		if o.def == nil {
+			return fmt.Errorf("option %q: no default", o.name)
+		}
+		if len(o.choices) == 0 {
+			return fmt.Errorf("option %q: combo without choices", o.name)
+		}
+		found := false
+		for _, c := range o.choices {
+			if strings.EqualFold(c, *o.def) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("option %q: default %q is not among choices", o.name, *o.def)
+		}
+	case "string":
+		if o.def == nil {
+			return fmt.Errorf("option %q: no default", o.name)
+		}
+	case "button":
+	default:
+		return fmt.Errorf("option %q: unknown type %q", o.name, o.typ)
+	}
+	return nil
+}
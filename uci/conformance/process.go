@@ -0,0 +1,74 @@
+package conformance
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+type Direction uint8
+
+const (
+	DirSend Direction = iota
+	DirRecv
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirSend:
+		return "send"
+	case DirRecv:
+		return "recv"
+	default:
+		return "?"
+	}
+}
+
+type Line struct {
+	Dir  Direction
+	Time time.Time
+	Text string
+}
+
+type recorder struct {
+	p uci.Process
+
+	mu    sync.Mutex
+	lines []Line
+}
+
+func newRecorder(p uci.Process) *recorder {
+	return &recorder{p: p}
+}
+
+func (r *recorder) add(dir Direction, s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, Line{Dir: dir, Time: time.Now(), Text: s})
+}
+
+func (r *recorder) Lines() []Line {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.lines)
+}
+
+func (r *recorder) Send(s string) error {
+	r.add(DirSend, s)
+	return r.p.Send(s)
+}
+
+func (r *recorder) Recv() (string, error) {
+	s, err := r.p.Recv()
+	if err != nil {
+		return "", err
+	}
+	r.add(DirRecv, s)
+	return s, nil
+}
+
+func (r *recorder) Done() <-chan struct{} { return r.p.Done() }
+func (r *recorder) Err() error            { return r.p.Err() }
+func (r *recorder) Kill()                 { r.p.Kill() }
@@ -0,0 +1,460 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+const settleDelay = 100 * time.Millisecond
+
+var bestMoveFENs = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4",
+	"8/P7/8/8/8/8/7k/K7 w - - 0 1",
+	"k7/8/8/8/8/8/8/1R5K b - - 0 1",
+	"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+}
+
+func DefaultScenarios() []Scenario {
+	return []Scenario{
+		{Name: "handshake", Run: checkHandshake},
+		{Name: "isready", Run: checkIsReady},
+		{Name: "bestmove", Run: checkBestMove},
+		{Name: "stop", Run: checkStop},
+		{Name: "ponder", Run: checkPonder},
+		{Name: "searchmoves", Run: checkSearchMoves},
+		{Name: "depth", Run: checkDepth},
+		{Name: "nodes", Run: checkNodes},
+		{Name: "movetime", Run: checkMovetime},
+	}
+}
+
+func firstToken(s string) string {
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return ""
+	}
+	return f[0]
+}
+
+func (e *Env) ping(ctx context.Context) (bool, error) {
+	pctx, cancel := context.WithTimeout(ctx, e.o.ReadyTimeout)
+	defer cancel()
+	err := e.e.Ping(pctx)
+	if err == nil {
+		return true, nil
+	}
+	if pctx.Err() != nil && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, err
+}
+
+func (e *Env) setPosition(ctx context.Context, fen string) error {
+	g, err := chess.NewGameWithFEN(fen)
+	if err != nil {
+		return fmt.Errorf("bad fen: %w", err)
+	}
+	if err := e.e.SetPosition(ctx, g); err != nil {
+		return fmt.Errorf("set position: %w", err)
+	}
+	return nil
+}
+
+func (e *Env) wait(ctx context.Context, s *uci.Search, timeout time.Duration) (bool, error) {
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case <-s.Done():
+		return true, nil
+	case <-e.e.Done():
+		return false, fmt.Errorf("engine terminated")
+	case <-wctx.Done():
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+}
+
+// Stops the search which failed to finish in time. If it doesn't stop, the engine is considered
+// unresponsive and the scenario is aborted.
+func (e *Env) recover(ctx context.Context, s *uci.Search) error {
+	if err := s.Stop(ctx, false); err != nil {
+		return fmt.Errorf("stop: %w", err)
+	}
+	ok, err := e.wait(ctx, s, e.o.StopTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("engine does not respond")
+	}
+	return nil
+}
+
+// Returns the time between the last "go" sent and the "bestmove" that followed it.
+func (e *Env) lastSearchTime() (time.Duration, bool) {
+	lines := e.Transcript()
+	var (
+		start time.Time
+		res   time.Duration
+		found bool
+	)
+	for _, ln := range lines {
+		switch {
+		case ln.Dir == DirSend && firstToken(ln.Text) == "go":
+			start = ln.Time
+			found = false
+		case ln.Dir == DirRecv && firstToken(ln.Text) == "bestmove" && !start.IsZero() && !found:
+			res = ln.Time.Sub(start)
+			found = true
+		}
+	}
+	return res, found
+}
+
+type infoStats struct {
+	mu       sync.Mutex
+	maxDepth int
+	maxNodes int64
+}
+
+func (st *infoStats) consume(_ *uci.Search, info uci.Info) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if d, ok := info.Depth.TryGet(); ok && d > st.maxDepth {
+		st.maxDepth = d
+	}
+	if n, ok := info.Nodes.TryGet(); ok && n > st.maxNodes {
+		st.maxNodes = n
+	}
+}
+
+func checkHandshake(ctx context.Context, env *Env) error {
+	hasName := false
+	numOpts, badOpts := 0, 0
+loop:
+	for _, ln := range env.Transcript() {
+		if ln.Dir != DirRecv {
+			continue
+		}
+		switch firstToken(ln.Text) {
+		case "uciok":
+			break loop
+		case "":
+		case "id":
+			if f := strings.Fields(ln.Text); len(f) >= 2 && f[1] == "name" {
+				hasName = true
+			}
+		case "option":
+			numOpts++
+			if err := checkOptionLine(ln.Text); err != nil {
+				badOpts++
+				env.Violatef("bad option: %v", err)
+			}
+		case "info":
+			env.Violatef("info before \"uciok\": %q", ln.Text)
+		default:
+			env.Violatef("unexpected line before \"uciok\": %q", ln.Text)
+		}
+	}
+	if !hasName {
+		env.Violatef("no \"id name\" sent")
+	}
+	if accepted := len(env.Engine().ListOpts()); accepted != numOpts-badOpts {
+		env.Violatef("%v option(s) rejected by the parser", numOpts-badOpts-accepted)
+	}
+	ok, err := env.ping(ctx)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if !ok {
+		env.Violatef("late \"readyok\": no reply in %v", env.o.ReadyTimeout)
+	}
+	return nil
+}
+
+func checkIsReady(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	ok, err := env.ping(ctx)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if !ok {
+		env.Violatef("late \"readyok\": no reply in %v", env.o.ReadyTimeout)
+	}
+
+	if err := env.setPosition(ctx, bestMoveFENs[0]); err != nil {
+		return err
+	}
+	s, err := e.Go(ctx, uci.GoOptions{Infinite: true}, nil)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	ok, err = env.ping(ctx)
+	if err != nil {
+		return fmt.Errorf("ping during search: %w", err)
+	}
+	if !ok {
+		env.Violatef("late \"readyok\" during search: no reply in %v", env.o.ReadyTimeout)
+	}
+	return env.recover(ctx, s)
+}
+
+func checkBestMove(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	for _, fen := range bestMoveFENs {
+		if err := e.UCINewGame(ctx, false); err != nil {
+			return fmt.Errorf("ucinewgame: %w", err)
+		}
+		if err := env.setPosition(ctx, fen); err != nil {
+			return err
+		}
+		s, err := e.Go(ctx, uci.GoOptions{Depth: maybe.Some(env.o.Depth)}, nil)
+		if err != nil {
+			return fmt.Errorf("go: %w", err)
+		}
+		ok, err := env.wait(ctx, s, env.o.SearchTimeout)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			env.Violatef("missing \"bestmove\" for %q in %v", fen, env.o.SearchTimeout)
+			if err := env.recover(ctx, s); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := s.BestMove(); err != nil {
+			env.Violatef("illegal \"bestmove\" for %q: %v", fen, err)
+		}
+	}
+	return nil
+}
+
+func checkStop(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	if err := env.setPosition(ctx, bestMoveFENs[0]); err != nil {
+		return err
+	}
+	s, err := e.Go(ctx, uci.GoOptions{Infinite: true}, nil)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	time.Sleep(settleDelay)
+	if s.Stopped() {
+		env.Violatef("\"bestmove\" sent during infinite search before \"stop\"")
+		return nil
+	}
+	if err := s.Stop(ctx, false); err != nil {
+		return fmt.Errorf("stop: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.StopTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("engine ignores \"stop\": no \"bestmove\" in %v", env.o.StopTimeout)
+		return nil
+	}
+	if _, err := s.BestMove(); err != nil {
+		env.Violatef("illegal \"bestmove\" after \"stop\": %v", err)
+	}
+	return nil
+}
+
+func checkPonder(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	if !e.PonderSupported() {
+		env.Skip("engine does not support pondering")
+		return nil
+	}
+	if err := e.SetPonder(ctx, true); err != nil {
+		return fmt.Errorf("enable ponder: %w", err)
+	}
+	g, err := chess.GameFromUCIList(chess.InitialBoard(), "e2e4 e7e5")
+	if err != nil {
+		panic(err)
+	}
+	if err := e.SetPosition(ctx, g); err != nil {
+		return fmt.Errorf("set position: %w", err)
+	}
+	budget := 2 * env.o.Movetime
+	spec := clock.UCITimeSpec{Wtime: budget, Btime: budget}
+
+	s, err := e.Go(ctx, uci.GoOptions{Ponder: true, TimeSpec: maybe.Some(spec)}, nil)
+	if err != nil {
+		return fmt.Errorf("go ponder: %w", err)
+	}
+	time.Sleep(settleDelay)
+	if s.Stopped() {
+		env.Violatef("\"bestmove\" sent during ponder before \"ponderhit\"")
+	} else {
+		if err := s.PonderHit(ctx); err != nil {
+			return fmt.Errorf("ponderhit: %w", err)
+		}
+		ok, err := env.wait(ctx, s, budget+env.o.TimeSlack)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			env.Violatef("no \"bestmove\" after \"ponderhit\" within the remaining time %v", budget)
+			if err := env.recover(ctx, s); err != nil {
+				return err
+			}
+		} else if _, err := s.BestMove(); err != nil {
+			env.Violatef("bad \"bestmove\" after \"ponderhit\": %v", err)
+		}
+	}
+
+	if err := e.SetPosition(ctx, g); err != nil {
+		return fmt.Errorf("set position: %w", err)
+	}
+	s, err = e.Go(ctx, uci.GoOptions{Ponder: true, TimeSpec: maybe.Some(spec)}, nil)
+	if err != nil {
+		return fmt.Errorf("go ponder: %w", err)
+	}
+	time.Sleep(settleDelay)
+	if s.Stopped() {
+		env.Violatef("\"bestmove\" sent during ponder before \"stop\"")
+		return nil
+	}
+	if err := s.Stop(ctx, false); err != nil {
+		return fmt.Errorf("stop: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.StopTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("engine ignores \"stop\" during ponder")
+	}
+	return nil
+}
+
+func checkSearchMoves(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	b := chess.InitialBoard()
+	allowed := make([]chess.Move, 0, 2)
+	for _, u := range []string{"a2a3", "h2h3"} {
+		m, err := chess.LegalMoveFromUCI(u, b)
+		if err != nil {
+			panic(err)
+		}
+		allowed = append(allowed, m)
+	}
+	if err := env.setPosition(ctx, b.FEN()); err != nil {
+		return err
+	}
+	s, err := e.Go(ctx, uci.GoOptions{
+		SearchMoves: allowed,
+		Depth:       maybe.Some(env.o.Depth),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.SearchTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("missing \"bestmove\" in %v", env.o.SearchTimeout)
+		return env.recover(ctx, s)
+	}
+	best, err := s.BestMove()
+	if err != nil {
+		env.Violatef("illegal \"bestmove\": %v", err)
+		return nil
+	}
+	if best != allowed[0] && best != allowed[1] {
+		env.Violatef("\"bestmove\" %v is not in searchmoves", best)
+	}
+	return nil
+}
+
+func checkDepth(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	if err := env.setPosition(ctx, bestMoveFENs[1]); err != nil {
+		return err
+	}
+	var st infoStats
+	s, err := e.Go(ctx, uci.GoOptions{Depth: maybe.Some(env.o.Depth)}, st.consume)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.SearchTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("missing \"bestmove\" for depth %v in %v", env.o.Depth, env.o.SearchTimeout)
+		return env.recover(ctx, s)
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if int64(st.maxDepth) > env.o.Depth {
+		env.Violatef("reported depth %v exceeds the limit %v", st.maxDepth, env.o.Depth)
+	}
+	return nil
+}
+
+func checkNodes(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	if err := env.setPosition(ctx, bestMoveFENs[1]); err != nil {
+		return err
+	}
+	var st infoStats
+	s, err := e.Go(ctx, uci.GoOptions{Nodes: maybe.Some(env.o.Nodes)}, st.consume)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.SearchTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("missing \"bestmove\" for %v nodes in %v", env.o.Nodes, env.o.SearchTimeout)
+		return env.recover(ctx, s)
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.maxNodes > env.o.Nodes+env.o.NodesSlack {
+		env.Violatef("reported nodes %v exceed the limit %v", st.maxNodes, env.o.Nodes)
+	}
+	return nil
+}
+
+func checkMovetime(ctx context.Context, env *Env) error {
+	e := env.Engine()
+	if err := env.setPosition(ctx, bestMoveFENs[1]); err != nil {
+		return err
+	}
+	s, err := e.Go(ctx, uci.GoOptions{Movetime: maybe.Some(env.o.Movetime)}, nil)
+	if err != nil {
+		return fmt.Errorf("go: %w", err)
+	}
+	ok, err := env.wait(ctx, s, env.o.Movetime+env.o.TimeSlack)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		env.Violatef("no \"bestmove\" within movetime %v", env.o.Movetime)
+		return env.recover(ctx, s)
+	}
+	if d, ok := env.lastSearchTime(); ok && d > env.o.Movetime+env.o.TimeSlack {
+		env.Violatef("search took %v with movetime %v", d, env.o.Movetime)
+	}
+	if _, err := s.BestMove(); err != nil {
+		env.Violatef("illegal \"bestmove\": %v", err)
+	}
+	return nil
+}
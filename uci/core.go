@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"sync/atomic"
+	"time"
 )
 
 var errTerminated = errors.New("process terminated")
@@ -19,6 +21,7 @@ type state interface {
 	Start(ctx context.Context, p Process) error
 	ProcessCommand(cmd command) (command, any, error)
 	ProcessMessage(msg string) error
+	CommandSent(cmd command, at time.Time)
 	Initialized() bool
 	Finish()
 }
@@ -108,7 +111,7 @@ loop:
 			case <-c.ctx.Done():
 			default:
 				if !errors.Is(err, io.EOF) {
-					c.l.Printf("cannot receive line from engine: %v", err)
+					logRecord(c.l, LogRecord{Level: slog.LevelError}, "cannot receive line from engine: %v", err)
 				}
 			}
 			break
@@ -123,10 +126,10 @@ loop:
 	select {
 	case <-c.p.Done():
 		if err := c.p.Err(); err != nil {
-			c.l.Printf("engine terminated badly: %v", err)
+			logRecord(c.l, LogRecord{Level: slog.LevelError}, "engine terminated badly: %v", err)
 		}
 	default:
-		c.l.Printf("killing engine")
+		logRecord(c.l, LogRecord{Level: slog.LevelInfo}, "killing engine")
 		c.p.Kill()
 	}
 	<-c.procDone
@@ -137,7 +140,7 @@ func (c *core) processLoop() {
 	defer c.s.Finish()
 
 	if err := c.s.Start(c.ctx, c.p); err != nil {
-		c.l.Printf("cannot start: %v", err)
+		logRecord(c.l, LogRecord{Level: slog.LevelError}, "cannot start: %v", err)
 		c.Cancel()
 		return
 	}
@@ -150,7 +153,8 @@ func (c *core) processLoop() {
 		select {
 		case msg := <-c.msgCh:
 			if err := c.s.ProcessMessage(msg); err != nil {
-				c.l.Printf("bad line: %v", err)
+				r := LogRecord{Level: slog.LevelWarn, Dir: LogDirRecv, Command: commandType(msg)}
+				logRecord(c.l, r, "bad line: %v", err)
 			}
 		case cmd := <-cmdCh:
 			realCmd, res, err := c.s.ProcessCommand(cmd.cmd)
@@ -164,10 +168,11 @@ func (c *core) processLoop() {
 			}
 			if canSend {
 				if err := c.p.Send(realCmd.Serialize()); err != nil {
-					c.l.Printf("cannot send command: %v", err)
+					logRecord(c.l, LogRecord{Level: slog.LevelError}, "cannot send command: %v", err)
 					c.Cancel()
 					return
 				}
+				c.s.CommandSent(realCmd, time.Now())
 			}
 		case <-c.ctx.Done():
 			return
@@ -3,6 +3,7 @@ package uci
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"slices"
 	"syscall"
@@ -14,6 +15,8 @@ type EasyEngineOptions struct {
 	Env             []string
 	Dir             string
 	SysProcAttr     *syscall.SysProcAttr
+	Limits          ProcessLimits
+	Stderr          io.Writer
 	Logger          Logger
 	EnableTracing   bool
 	TracingOptions  TracingProcessOptions
@@ -26,7 +29,13 @@ func NewEasyEngine(ctx context.Context, o EasyEngineOptions) (*Engine, error) {
 	cmd.Env = slices.Clone(o.Env)
 	cmd.Dir = o.Dir
 	cmd.SysProcAttr = o.SysProcAttr
-	p, err := NewCmdProcess(cmd)
+	cmd.Stderr = o.Stderr
+	hooks, cleanup, err := setupLimits(cmd, o.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("set up limits: %w", err)
+	}
+	p, err := newCmdProcess(cmd, hooks)
+	cleanup()
 	if err != nil {
 		return nil, fmt.Errorf("create process: %w", err)
 	}
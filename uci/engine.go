@@ -3,6 +3,7 @@ package uci
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/alex65536/go-chess/chess"
@@ -32,6 +33,11 @@ type EngineOptions struct {
 	//
 	// Zero means default.
 	WaitOnCancelTimeout time.Duration
+
+	// Searches which leave less time on the clock are counted in TimingStats.NearDeadline.
+	//
+	// Zero means default.
+	NearDeadline time.Duration
 }
 
 func (o EngineOptions) Clone() EngineOptions {
@@ -45,6 +51,9 @@ func (o *EngineOptions) FillDefaults() {
 	if o.WaitOnCancelTimeout == 0 {
 		o.WaitOnCancelTimeout = 500 * time.Millisecond
 	}
+	if o.NearDeadline == 0 {
+		o.NearDeadline = 50 * time.Millisecond
+	}
 }
 
 func (o *EngineOptions) coderOptions() coderOptions {
@@ -92,7 +101,7 @@ func (e *Engine) waitInitializedThread() {
 	ctx, cancel := context.WithTimeout(context.Background(), e.o.InitTimeout)
 	defer cancel()
 	if err := e.WaitInitialized(ctx); err != nil {
-		e.l.Printf("wait initialized failed: %v", err)
+		logRecord(e.l, LogRecord{Level: slog.LevelError}, "wait initialized failed: %v", err)
 		e.Cancel()
 		return
 	}
@@ -111,7 +120,7 @@ func (e *Engine) watchCtxThread(watchedCtx context.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), e.o.WaitOnCancelTimeout)
 	defer cancel()
 	if err := e.Quit(ctx, true); err != nil {
-		e.l.Printf("engine has not terminated gracefully: %v", err)
+		logRecord(e.l, LogRecord{Level: slog.LevelWarn}, "engine has not terminated gracefully: %v", err)
 		return
 	}
 }
@@ -294,15 +303,19 @@ func (e *Engine) CurSearch() *Search {
 	return &Search{s: s, e: e}
 }
 
-func (e *Engine) Info() (EngineInfo, bool)  { return e.s.Info() }
-func (e *Engine) Initialized() bool         { return e.s.Initialized() }
-func (e *Engine) Terminating() bool         { return e.s.Terminating() }
-func (e *Engine) Debug() bool               { return e.s.Debug() }
-func (e *Engine) GetOpt(name string) Option { return e.s.GetOpt(name) }
-func (e *Engine) ListOpts() []string        { return e.s.ListOpts() }
-func (e *Engine) PonderSupported() bool     { return e.s.PonderSupported() }
-func (e *Engine) Ponder() bool              { return e.s.Ponder() }
-
+func (e *Engine) Info() (EngineInfo, bool)           { return e.s.Info() }
+func (e *Engine) Initialized() bool                  { return e.s.Initialized() }
+func (e *Engine) Terminating() bool                  { return e.s.Terminating() }
+func (e *Engine) Debug() bool                        { return e.s.Debug() }
+func (e *Engine) GetOpt(name string) Option          { return e.s.GetOpt(name) }
+func (e *Engine) GetOptDefault(name string) OptValue { return e.s.GetOptDefault(name) }
+func (e *Engine) ListOpts() []string                 { return e.s.ListOpts() }
+func (e *Engine) PonderSupported() bool              { return e.s.PonderSupported() }
+func (e *Engine) Ponder() bool                       { return e.s.Ponder() }
+func (e *Engine) TimingStats() TimingStats           { return e.s.TimingStats() }
+
+func (s *Search) ID() uint64                            { return s.s.ID() }
+func (s *Search) Timing() SearchTiming                  { return s.s.Timing() }
 func (s *Search) Done() <-chan struct{}                 { return s.s.Done() }
 func (s *Search) Err() error                            { return s.s.Err() }
 func (s *Search) Status() SearchStatus                  { return s.s.Status() }
@@ -0,0 +1,239 @@
+// This package loads engine definitions from the files in cutechess-cli "engines.json" format and
+// turns them into running UCI engines.
+package enginecfg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+type RestartMode string
+
+const (
+	RestartAuto RestartMode = "auto"
+	RestartOn   RestartMode = "on"
+	RestartOff  RestartMode = "off"
+)
+
+func (m RestartMode) Validate() error {
+	switch m {
+	case "", RestartAuto, RestartOn, RestartOff:
+		return nil
+	default:
+		return fmt.Errorf("bad restart mode %q", string(m))
+	}
+}
+
+type Option struct {
+	Name string `json:"name"`
+
+	// Either string, bool or float64, as decoded from JSON.
+	Value any `json:"value"`
+
+	// The fields below are informational only. Type checking is done against the options
+	// advertised by the engine itself.
+	Type    string   `json:"type,omitempty"`
+	Default any      `json:"default,omitempty"`
+	Min     *int64   `json:"min,omitempty"`
+	Max     *int64   `json:"max,omitempty"`
+	Choices []string `json:"choices,omitempty"`
+}
+
+type EngineConfig struct {
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Args        []string `json:"arguments,omitempty"`
+	WorkingDir  string   `json:"workingDirectory,omitempty"`
+	StderrFile  string   `json:"stderrFile,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
+	InitStrings []string `json:"initStrings,omitempty"`
+	Ponder      bool     `json:"ponder,omitempty"`
+	Variants    []string `json:"variants,omitempty"`
+	Options     []Option `json:"options,omitempty"`
+
+	// Scores in white's point of view. Not supported yet, so it must be false.
+	WhitePOV bool `json:"whitepov,omitempty"`
+
+	// The engines are never restarted between games, unless they crash. So, RestartOn is not
+	// supported yet, and the other modes all mean the same.
+	Restart RestartMode `json:"restart,omitempty"`
+
+	// Extra environment variables in "KEY=VALUE" form. This field is not supported by cutechess.
+	Env []string `json:"env,omitempty"`
+}
+
+func (c EngineConfig) Clone() EngineConfig {
+	c.Args = slices.Clone(c.Args)
+	c.InitStrings = slices.Clone(c.InitStrings)
+	c.Variants = slices.Clone(c.Variants)
+	c.Options = slices.Clone(c.Options)
+	c.Env = slices.Clone(c.Env)
+	return c
+}
+
+func (c *EngineConfig) Validate() error {
+	if c.Command == "" {
+		return fmt.Errorf("no command")
+	}
+	if c.Protocol != "" && c.Protocol != "uci" {
+		return fmt.Errorf("unsupported protocol %q", c.Protocol)
+	}
+	if err := c.Restart.Validate(); err != nil {
+		return err
+	}
+	if c.Restart == RestartOn {
+		return fmt.Errorf("restart mode %q is unsupported", string(c.Restart))
+	}
+	if c.WhitePOV {
+		return fmt.Errorf("whitepov is unsupported")
+	}
+	if len(c.Variants) != 0 && !slices.Contains(c.Variants, "standard") {
+		return fmt.Errorf("standard chess is not supported by the engine")
+	}
+	for _, s := range c.InitStrings {
+		if _, err := parseInitString(s); err != nil {
+			return fmt.Errorf("init string %q: %w", s, err)
+		}
+	}
+	for _, e := range c.Env {
+		if !strings.Contains(e, "=") {
+			return fmt.Errorf("bad env entry %q", e)
+		}
+	}
+	return nil
+}
+
+func Load(r io.Reader) ([]EngineConfig, error) {
+	var cs []EngineConfig
+	if err := json.NewDecoder(r).Decode(&cs); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	for i := range cs {
+		if err := cs[i].Validate(); err != nil {
+			return nil, fmt.Errorf("engine #%v (%q): %w", i+1, cs[i].Name, err)
+		}
+	}
+	return cs, nil
+}
+
+func LoadFile(path string) ([]EngineConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func Find(cs []EngineConfig, name string) (EngineConfig, bool) {
+	for _, c := range cs {
+		if c.Name == name {
+			return c.Clone(), true
+		}
+	}
+	return EngineConfig{}, false
+}
+
+// Splits the command line in the same way as cutechess does: arguments are separated by spaces,
+// and double quotes group the arguments with spaces.
+func splitCommand(s string) ([]string, error) {
+	var (
+		res     []string
+		cur     strings.Builder
+		inQuote bool
+		hasCur  bool
+	)
+	for i := range len(s) {
+		b := s[i]
+		switch {
+		case b == '"':
+			inQuote = !inQuote
+			hasCur = true
+		case b == ' ' && !inQuote:
+			if hasCur {
+				res = append(res, cur.String())
+				cur.Reset()
+				hasCur = false
+			}
+		default:
+			_ = cur.WriteByte(b)
+			hasCur = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if hasCur {
+		res = append(res, cur.String())
+	}
+	return res, nil
+}
+
+// EasyEngineOptions fills the process-related fields of base according to the config. The
+// remaining fields of base are left intact.
+func (c *EngineConfig) EasyEngineOptions(base uci.EasyEngineOptions) (uci.EasyEngineOptions, error) {
+	if err := c.Validate(); err != nil {
+		return uci.EasyEngineOptions{}, fmt.Errorf("validate: %w", err)
+	}
+	cmd, err := splitCommand(c.Command)
+	if err != nil {
+		return uci.EasyEngineOptions{}, fmt.Errorf("parse command: %w", err)
+	}
+	if len(cmd) == 0 {
+		return uci.EasyEngineOptions{}, fmt.Errorf("empty command")
+	}
+	o := base
+	o.Name = cmd[0]
+	o.Args = append(cmd[1:], c.Args...)
+	o.Dir = c.WorkingDir
+	if len(c.Env) != 0 {
+		o.Env = append(os.Environ(), c.Env...)
+	}
+	if o.TracingOptions.ProcessName == "" {
+		o.TracingOptions.ProcessName = c.Name
+	}
+	return o, nil
+}
+
+// NewEngine starts the engine and configures it. Process-related fields of base are overridden by
+// the config.
+func (c *EngineConfig) NewEngine(ctx context.Context, base uci.EasyEngineOptions) (*uci.Engine, error) {
+	o, err := c.EasyEngineOptions(base)
+	if err != nil {
+		return nil, err
+	}
+	o.WaitInitialized = true
+	var stderr *os.File
+	if c.StderrFile != "" {
+		stderr, err = os.OpenFile(c.StderrFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open stderr file: %w", err)
+		}
+		o.Stderr = stderr
+	}
+	e, err := uci.NewEasyEngine(ctx, o)
+	if err != nil {
+		if stderr != nil {
+			_ = stderr.Close()
+		}
+		return nil, err
+	}
+	if stderr != nil {
+		go func() {
+			<-e.Done()
+			_ = stderr.Close()
+		}()
+	}
+	if err := c.Configure(ctx, e); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("configure: %w", err)
+	}
+	return e, nil
+}
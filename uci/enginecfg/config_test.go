@@ -0,0 +1,148 @@
+package enginecfg
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const enginesJSON = `[
+	{
+		"name": "Stockfish",
+		"command": "\"/opt/my engines/stockfish\" --bench-off",
+		"arguments": ["--threads-hint", "4"],
+		"workingDirectory": "/opt/engines",
+		"protocol": "uci",
+		"initStrings": ["setoption name Clear Hash", "setoption name Style value Risky"],
+		"ponder": true,
+		"restart": "off",
+		"variants": ["standard", "fischerandom"],
+		"options": [
+			{"name": "Hash", "type": "spin", "value": 128, "default": 16, "min": 1, "max": 1024},
+			{"name": "UCI_ShowWDL", "type": "check", "value": true},
+			{"name": "SyzygyPath", "type": "string", "value": "/tb"}
+		]
+	},
+	{
+		"name": "Other",
+		"command": "other"
+	}
+]`
+
+var engineOptions = []string{
+	"option name Hash type spin default 16 min 1 max 1024",
+	"option name UCI_ShowWDL type check default false",
+	"option name SyzygyPath type string default <empty>",
+	"option name Style type combo default Solid var Solid var Risky",
+	"option name Clear Hash type button",
+	"option name Ponder type check default false",
+}
+
+func TestLoad(t *testing.T) {
+	cs, err := Load(strings.NewReader(enginesJSON))
+	require.NoError(t, err)
+	require.Len(t, cs, 2)
+
+	c, ok := Find(cs, "Stockfish")
+	require.True(t, ok)
+	assert.Equal(t, RestartOff, c.Restart)
+	assert.True(t, c.Ponder)
+	require.Len(t, c.Options, 3)
+	assert.Equal(t, float64(128), c.Options[0].Value)
+
+	o, err := c.EasyEngineOptions(uci.EasyEngineOptions{EnableTracing: true})
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/my engines/stockfish", o.Name)
+	assert.Equal(t, []string{"--bench-off", "--threads-hint", "4"}, o.Args)
+	assert.Equal(t, "/opt/engines", o.Dir)
+	assert.Equal(t, "Stockfish", o.TracingOptions.ProcessName)
+	assert.True(t, o.EnableTracing)
+
+	_, ok = Find(cs, "Missing")
+	assert.False(t, ok)
+
+	for _, bad := range []string{
+		`[{"name": "A"}]`,
+		`[{"name": "A", "command": "a", "protocol": "xboard"}]`,
+		`[{"name": "A", "command": "a", "restart": "sometimes"}]`,
+		`[{"name": "A", "command": "a", "restart": "on"}]`,
+		`[{"name": "A", "command": "a", "whitepov": true}]`,
+		`[{"name": "A", "command": "a", "initStrings": ["debug on"]}]`,
+		`[{"name": "A", "command": "a", "variants": ["crazyhouse"]}]`,
+	} {
+		_, err := Load(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	cs, err := Load(strings.NewReader(enginesJSON))
+	require.NoError(t, err)
+	c := cs[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newEngine := func() (*ucitest.Engine, *uci.Engine) {
+		fe := ucitest.NewEngine(ucitest.EngineOptions{Options: engineOptions})
+		e := uci.NewEngine(ctx, fe, nil, uci.EngineOptions{})
+		require.NoError(t, e.WaitInitialized(ctx))
+		return fe, e
+	}
+
+	fe, e := newEngine()
+	require.NoError(t, c.Configure(ctx, e))
+	require.NoError(t, e.Ping(ctx))
+	assert.Equal(t, []string{
+		"uci",
+		"setoption name Clear Hash",
+		"setoption name Style value Risky",
+		"setoption name Hash value 128",
+		"setoption name UCI_ShowWDL value true",
+		"setoption name SyzygyPath value /tb",
+		"setoption name Ponder value true",
+		"isready",
+	}, fe.Received())
+	e.Close()
+
+	for _, bad := range []Option{
+		{Name: "Hash", Value: float64(4096)},
+		{Name: "Hash", Value: 1.5},
+		{Name: "UCI_ShowWDL", Value: "maybe"},
+		{Name: "Style", Value: "Wild"},
+		{Name: "NoSuchOption", Value: "1"},
+	} {
+		bc := c.Clone()
+		bc.InitStrings = nil
+		bc.Options = append(bc.Options, bad)
+		fe, e := newEngine()
+		assert.Error(t, bc.Configure(ctx, e), bad.Name)
+		require.NoError(t, e.Ping(ctx))
+		// Nothing must be sent if some option is invalid.
+		assert.Equal(t, []string{"uci", "isready"}, fe.Received())
+		e.Close()
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		res []string
+	}{
+		{s: "stockfish", res: []string{"stockfish"}},
+		{s: "  engine  -a b ", res: []string{"engine", "-a", "b"}},
+		{s: `"my engine" "" x`, res: []string{"my engine", "", "x"}},
+	} {
+		res, err := splitCommand(tc.s)
+		require.NoError(t, err)
+		assert.Equal(t, tc.res, res)
+	}
+	_, err := splitCommand(`"unterminated`)
+	assert.Error(t, err)
+}
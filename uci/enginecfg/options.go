@@ -0,0 +1,130 @@
+package enginecfg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+type setting struct {
+	name  string
+	value any
+}
+
+func parseInitString(s string) (setting, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "setoption name ")
+	if !ok {
+		return setting{}, fmt.Errorf("only \"setoption\" is supported")
+	}
+	name, value, ok := strings.Cut(rest, " value ")
+	if !ok {
+		// Button
+		return setting{name: strings.TrimSpace(rest), value: nil}, nil
+	}
+	return setting{name: strings.TrimSpace(name), value: strings.TrimSpace(value)}, nil
+}
+
+func toBool(v any) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		switch x {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return false, fmt.Errorf("%q is not a boolean", x)
+	default:
+		return false, fmt.Errorf("expected boolean, got %T", v)
+	}
+}
+
+func toString(v any) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	default:
+		return "", fmt.Errorf("expected string, got %T", v)
+	}
+}
+
+// Converts the value from the config into the value suitable for the engine option. The second
+// returned value indicates whether the option must be sent.
+func convertValue(e *uci.Engine, name string, v any) (uci.OptValue, bool, error) {
+	if _, ok := e.GetOpt(name).(*uci.OptionButton); ok {
+		// Buttons are pressed only if the value is absent or true.
+		if v == nil {
+			return uci.OptValueButton{}, true, nil
+		}
+		b, err := toBool(v)
+		if err != nil {
+			return nil, false, fmt.Errorf("option %q: %w", name, err)
+		}
+		return uci.OptValueButton{}, b, nil
+	}
+	s, err := toString(v)
+	if err != nil {
+		return nil, false, fmt.Errorf("option %q: %w", name, err)
+	}
+	val, err := e.ParseOptValue(name, s)
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+func (c *EngineConfig) settings() ([]setting, error) {
+	var res []setting
+	for _, s := range c.InitStrings {
+		st, err := parseInitString(s)
+		if err != nil {
+			return nil, fmt.Errorf("init string %q: %w", s, err)
+		}
+		res = append(res, st)
+	}
+	for _, o := range c.Options {
+		res = append(res, setting{name: o.Name, value: o.Value})
+	}
+	return res, nil
+}
+
+// Configure applies the options from the config to the engine. All the options are checked
+// against the ones advertised by the engine before sending, so nothing is sent if at least one
+// option is invalid.
+func (c *EngineConfig) Configure(ctx context.Context, e *uci.Engine) error {
+	settings, err := c.settings()
+	if err != nil {
+		return err
+	}
+	var values []uci.OptionSetting
+	for _, st := range settings {
+		val, send, err := convertValue(e, st.name, st.value)
+		if err != nil {
+			return err
+		}
+		if send {
+			values = append(values, uci.OptionSetting{Name: st.name, Value: val})
+		}
+	}
+	if c.Ponder {
+		if !e.PonderSupported() {
+			return fmt.Errorf("pondering is not supported by the engine")
+		}
+		values = append(values, uci.OptionSetting{Name: "Ponder", Value: uci.OptValueBool(true)})
+	}
+	for _, v := range values {
+		if err := e.SetOption(ctx, v.Name, v.Value); err != nil {
+			return fmt.Errorf("set option %q: %w", v.Name, err)
+		}
+	}
+	return nil
+}
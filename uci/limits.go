@@ -0,0 +1,40 @@
+package uci
+
+import (
+	"time"
+)
+
+// ProcessLimits restricts the resources available to the engine process. Zero value means no
+// limits. Currently, the limits are supported only on Linux.
+//
+// MaxMemory and MaxCPUTime are set by running the engine via /bin/sh, which applies them with
+// ulimit and then execs the engine. So, they are in effect from the start and inherited by all
+// the processes spawned by the engine. POSIX shell cannot set argv[0] on exec, so the engine gets
+// the resolved binary path (Cmd.Path) as argv[0] instead of Cmd.Args[0].
+type ProcessLimits struct {
+	// Maximum size of the virtual address space, in bytes. Note that it also counts memory which is
+	// reserved but not used, so it must be set with some margin above the expected hash size.
+	MaxMemory int64
+
+	// Maximum CPU time consumed by the process. It is rounded up to whole seconds. The process is
+	// killed by the kernel once it exceeds the limit.
+	MaxCPUTime time.Duration
+
+	// Path to the cgroup v2 directory (e.g. "/sys/fs/cgroup/engines/sf1") in which the engine must
+	// be placed. The directory is created if it doesn't exist, and removed after the engine
+	// terminates in this case. Limits for the cgroup itself are not configured here.
+	CgroupPath string
+
+	// Run the engine in its own process group. Kill() then kills the entire group, including all
+	// the helper processes spawned by the engine. Remaining processes in the group are also killed
+	// when the engine terminates.
+	OwnProcessGroup bool
+}
+
+func (l ProcessLimits) Clone() ProcessLimits {
+	return l
+}
+
+func (l *ProcessLimits) IsZero() bool {
+	return *l == ProcessLimits{}
+}
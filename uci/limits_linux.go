@@ -0,0 +1,138 @@
+//go:build linux
+
+package uci
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const pidType = 1 // P_PID
+
+// Waits until the process terminates, but leaves it as zombie, so its PID cannot be reused.
+func waitNoReap(pid int) error {
+	var info [128]byte // siginfo_t
+	for {
+		_, _, errno := syscall.Syscall6(
+			syscall.SYS_WAITID,
+			pidType, uintptr(pid), uintptr(unsafe.Pointer(&info[0])),
+			syscall.WEXITED|syscall.WNOWAIT, 0, 0,
+		)
+		switch errno {
+		case 0:
+			return nil
+		case syscall.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+const shellPath = "/bin/sh"
+
+// Makes cmd run via shell, which sets the resource limits and then execs into the original
+// command. So, the limits are in effect from the very start, and all the processes spawned by the
+// engine inherit them.
+func wrapWithLimits(cmd *exec.Cmd, l ProcessLimits) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	if _, err := os.Stat(shellPath); err != nil {
+		return fmt.Errorf("find shell: %w", err)
+	}
+	var script strings.Builder
+	if l.MaxMemory != 0 {
+		// ulimit -v takes the value in KiB.
+		kib := max(l.MaxMemory/1024, 1)
+		fmt.Fprintf(&script, "ulimit -v %d || exit 126\n", kib)
+	}
+	if l.MaxCPUTime != 0 {
+		secs := int64((l.MaxCPUTime + time.Second - 1) / time.Second)
+		fmt.Fprintf(&script, "ulimit -t %d || exit 126\n", secs)
+	}
+	// "exec -a" is not POSIX, so the original argv[0] is replaced with the binary path.
+	_, _ = script.WriteString(`exec "$0" "$@"`)
+	args := append([]string{"sh", "-c", script.String(), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shellPath
+	cmd.Args = args
+	return nil
+}
+
+func setupLimits(cmd *exec.Cmd, l ProcessLimits) (cmdHooks, func(), error) {
+	var h cmdHooks
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	if l.MaxMemory != 0 || l.MaxCPUTime != 0 {
+		if err := wrapWithLimits(cmd, l); err != nil {
+			return cmdHooks{}, nil, fmt.Errorf("set rlimits: %w", err)
+		}
+	}
+
+	attr := &syscall.SysProcAttr{}
+	if cmd.SysProcAttr != nil {
+		*attr = *cmd.SysProcAttr
+	}
+	cmd.SysProcAttr = attr
+
+	if l.OwnProcessGroup {
+		attr.Setpgid = true
+		attr.Pgid = 0
+		h.kill = func(p *os.Process) error {
+			return syscall.Kill(-p.Pid, syscall.SIGKILL)
+		}
+		// Kill the remaining processes of the group while the leader is not reaped yet. Otherwise,
+		// the group may become empty and its ID may be reused by an unrelated process group.
+		h.beforeWait = func(p *os.Process) {
+			if err := waitNoReap(p.Pid); err == nil {
+				_ = syscall.Kill(-p.Pid, syscall.SIGKILL)
+			}
+		}
+	}
+
+	var afterWait []func()
+	if l.CgroupPath != "" {
+		created := false
+		if _, err := os.Stat(l.CgroupPath); errors.Is(err, fs.ErrNotExist) {
+			if err := os.Mkdir(l.CgroupPath, 0o755); err != nil {
+				return cmdHooks{}, nil, fmt.Errorf("create cgroup: %w", err)
+			}
+			created = true
+		}
+		f, err := os.Open(l.CgroupPath)
+		if err != nil {
+			if created {
+				_ = os.Remove(l.CgroupPath)
+			}
+			return cmdHooks{}, nil, fmt.Errorf("open cgroup: %w", err)
+		}
+		attr.UseCgroupFD = true
+		attr.CgroupFD = int(f.Fd())
+		// The descriptor is needed only to start the process.
+		cleanups = append(cleanups, func() { _ = f.Close() })
+		if created {
+			afterWait = append(afterWait, func() { _ = os.Remove(l.CgroupPath) })
+		}
+	}
+	if len(afterWait) != 0 {
+		h.afterWait = func() {
+			for _, f := range afterWait {
+				f()
+			}
+		}
+	}
+
+	return h, cleanup, nil
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package uci_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shellEngine = `
+echo $$ > "$1/engine.pid"
+sleep 1000 &
+echo $! > "$1/helper.pid"
+while read -r line; do
+	case "$line" in
+		uci) echo "id name Shell"; echo "uciok" ;;
+		isready) echo "readyok" ;;
+		quit) exit 0 ;;
+	esac
+done
+`
+
+func readPid(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+	return pid
+}
+
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	// Zombies are not considered alive.
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	_, rest, _ := strings.Cut(string(data), ") ")
+	return !strings.HasPrefix(rest, "Z")
+}
+
+func TestProcessLimits(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	e, err := uci.NewEasyEngine(ctx, uci.EasyEngineOptions{
+		Name: "/bin/sh",
+		Args: []string{"-c", shellEngine, "sh", dir},
+		Limits: uci.ProcessLimits{
+			MaxMemory:       1 << 30,
+			MaxCPUTime:      1500 * time.Millisecond,
+			OwnProcessGroup: true,
+		},
+		WaitInitialized: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, e.Ping(ctx))
+
+	pid := readPid(t, filepath.Join(dir, "engine.pid"))
+	helper := readPid(t, filepath.Join(dir, "helper.pid"))
+	// The helper is spawned right after the start, so it checks that the limits are applied before
+	// the engine runs.
+	for _, p := range []int{pid, helper} {
+		limits, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(p), "limits"))
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(limits), "\n") {
+			fields := strings.Fields(line)
+			switch {
+			case strings.HasPrefix(line, "Max address space"):
+				assert.Equal(t, []string{"1073741824", "1073741824"}, fields[3:5])
+			case strings.HasPrefix(line, "Max cpu time"):
+				assert.Equal(t, []string{"2", "2"}, fields[3:5])
+			}
+		}
+	}
+	pgid, err := syscall.Getpgid(pid)
+	require.NoError(t, err)
+	assert.Equal(t, pid, pgid)
+	assert.True(t, processAlive(helper))
+
+	// Killing the engine must also kill its helpers.
+	e.Close()
+	<-e.Done()
+	assert.Eventually(t, func() bool { return !processAlive(helper) }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package uci
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func setupLimits(_ *exec.Cmd, l ProcessLimits) (cmdHooks, func(), error) {
+	if !l.IsZero() {
+		return cmdHooks{}, nil, fmt.Errorf("process limits are not supported on this platform")
+	}
+	return cmdHooks{}, func() {}, nil
+}
@@ -1,9 +1,13 @@
 package uci
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
 type Logger interface {
@@ -37,3 +41,136 @@ type simpleLogger struct {
 func (l simpleLogger) Printf(msg string, args ...any) {
 	_, _ = fmt.Fprintf(l.w, msg+"\n", args...)
 }
+
+type LogDirection string
+
+const (
+	LogDirNone LogDirection = ""
+	LogDirSend LogDirection = "send"
+	LogDirRecv LogDirection = "recv"
+)
+
+// LogRecord is a structured log message. Zero fields are considered absent.
+type LogRecord struct {
+	Time     time.Time
+	Level    slog.Level
+	Msg      string
+	Engine   string
+	Dir      LogDirection
+	SearchID uint64
+	Command  string
+}
+
+// StructuredLogger is a logger which supports structured log records. If the logger passed to this
+// package implements it, then Log is used instead of Printf wherever possible.
+type StructuredLogger interface {
+	Logger
+	Log(r LogRecord)
+}
+
+type SlogLoggerOptions struct {
+	// Engine name added to each record, unless the record specifies its own name.
+	Engine string
+}
+
+func (o SlogLoggerOptions) Clone() SlogLoggerOptions {
+	return o
+}
+
+// NewSlogLogger creates an adapter for log/slog. Messages logged via Printf have info level.
+func NewSlogLogger(l *slog.Logger, o SlogLoggerOptions) StructuredLogger {
+	return &slogLogger{l: l, o: o.Clone()}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+	o SlogLoggerOptions
+}
+
+func (l *slogLogger) Printf(msg string, args ...any) {
+	l.Log(LogRecord{Level: slog.LevelInfo, Msg: fmt.Sprintf(msg, args...)})
+}
+
+func (l *slogLogger) Log(r LogRecord) {
+	ctx := context.Background()
+	if !l.l.Enabled(ctx, r.Level) {
+		return
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	rec := slog.NewRecord(r.Time, r.Level, r.Msg, 0)
+	if r.Engine == "" {
+		r.Engine = l.o.Engine
+	}
+	if r.Engine != "" {
+		rec.AddAttrs(slog.String("engine", r.Engine))
+	}
+	if r.Dir != LogDirNone {
+		rec.AddAttrs(slog.String("dir", string(r.Dir)))
+	}
+	if r.SearchID != 0 {
+		rec.AddAttrs(slog.Uint64("search_id", r.SearchID))
+	}
+	if r.Command != "" {
+		rec.AddAttrs(slog.String("cmd", r.Command))
+	}
+	_ = l.l.Handler().Handle(ctx, rec)
+}
+
+// Logs the message with fields from r. Non-structured loggers receive just the message.
+func logRecord(l Logger, r LogRecord, msg string, args ...any) {
+	sl, ok := l.(StructuredLogger)
+	if !ok {
+		l.Printf(msg, args...)
+		return
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	r.Msg = fmt.Sprintf(msg, args...)
+	sl.Log(r)
+}
+
+// Returns the logger which fills the absent fields of each record from r. Messages logged via
+// Printf get the level from r.
+func withRecord(l Logger, r LogRecord) Logger {
+	sl, ok := l.(StructuredLogger)
+	if !ok {
+		return l
+	}
+	return &recordLogger{l: sl, r: r}
+}
+
+type recordLogger struct {
+	l StructuredLogger
+	r LogRecord
+}
+
+func (l *recordLogger) Printf(msg string, args ...any) {
+	logRecord(l, l.r, msg, args...)
+}
+
+func (l *recordLogger) Log(r LogRecord) {
+	if r.Engine == "" {
+		r.Engine = l.r.Engine
+	}
+	if r.Dir == LogDirNone {
+		r.Dir = l.r.Dir
+	}
+	if r.SearchID == 0 {
+		r.SearchID = l.r.SearchID
+	}
+	if r.Command == "" {
+		r.Command = l.r.Command
+	}
+	l.l.Log(r)
+}
+
+// Returns the command type of the line, i.e. its first token.
+func commandType(line string) string {
+	if fs := strings.Fields(line); len(fs) != 0 {
+		return fs[0]
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package uci_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	l := uci.NewSlogLogger(
+		slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		uci.SlogLoggerOptions{Engine: "default"},
+	)
+	fe := ucitest.NewEngine(ucitest.EngineOptions{
+		Hook: func(e *ucitest.Engine, line string) bool {
+			if strings.HasPrefix(line, "go") {
+				e.Reply("info string thinking")
+				e.Reply("info depth 1 frobnicate")
+			}
+			return false
+		},
+	})
+	p := uci.NewTracingProcess(fe, l, uci.TracingProcessOptions{ProcessName: "fake"})
+	e := uci.NewEngine(ctx, p, l, uci.EngineOptions{LogEngineString: true})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+	require.NoError(t, e.SetPosition(ctx, chess.NewGame()))
+	s, err := e.Go(ctx, uci.GoOptions{Depth: maybe.Some[int64](1)}, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Wait(ctx))
+	require.NoError(t, e.Ping(ctx))
+	e.Close()
+	assert.Equal(t, uint64(1), s.ID())
+
+	type record struct {
+		Time     time.Time `json:"time"`
+		Level    string    `json:"level"`
+		Msg      string    `json:"msg"`
+		Engine   string    `json:"engine"`
+		Dir      string    `json:"dir"`
+		SearchID uint64    `json:"search_id"`
+		Cmd      string    `json:"cmd"`
+	}
+	var recs []record
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r record
+		require.NoError(t, dec.Decode(&r))
+		assert.False(t, r.Time.IsZero())
+		recs = append(recs, r)
+	}
+
+	has := func(want record) bool {
+		for _, r := range recs {
+			r.Time = time.Time{}
+			if want.Msg == "" {
+				r.Msg = ""
+			}
+			if r == want {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, has(record{Level: "DEBUG", Engine: "fake", Dir: "send", Cmd: "uci", Msg: "me -> fake: uci"}))
+	assert.True(t, has(record{Level: "DEBUG", Engine: "fake", Dir: "recv", Cmd: "uciok"}))
+	assert.True(t, has(record{Level: "DEBUG", Engine: "fake", Dir: "send", Cmd: "go"}))
+	assert.True(t, has(record{Level: "DEBUG", Engine: "fake", Dir: "recv", Cmd: "bestmove"}))
+	assert.True(t, has(record{
+		Level: "INFO", Engine: "default", Dir: "recv", SearchID: 1, Cmd: "info", Msg: "engine: thinking",
+	}))
+	assert.True(t, has(record{Level: "WARN", Engine: "default", Dir: "recv", SearchID: 1, Cmd: "info"}))
+}
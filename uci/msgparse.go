@@ -205,6 +205,22 @@ func parseOption(tok *tokenizer, l Logger) (optPair, error) {
 	return o, nil
 }
 
+// ParseInfo parses the "info" message sent by the engine. Recoverable parse errors are not returned,
+// but reported into l instead.
+func ParseInfo(msg string, l Logger) (Info, error) {
+	if l == nil {
+		l = NewNullLogger()
+	}
+	tok, err := newTokenizer(msg, coderOptions{})
+	if err != nil {
+		return Info{}, fmt.Errorf("tokenize %q: %w", msg, err)
+	}
+	if name, ok := tok.Next(); !ok || name != "info" {
+		return Info{}, fmt.Errorf("not an \"info\" message")
+	}
+	return parseInfo(tok, l)
+}
+
 func parseInfo(tok *tokenizer, l Logger) (Info, error) {
 	info := Info{}
 	parsed := make(map[string]struct{})
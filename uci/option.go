@@ -182,6 +182,7 @@ func (o *OptionString) setValue(v OptValue, co coderOptions) error {
 type optPair struct {
 	name  string
 	value Option
+	def   OptValue
 }
 
 var ponderOptName = caseFold("Ponder")
@@ -0,0 +1,306 @@
+package uci
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type OptionSetting struct {
+	Name  string
+	Value OptValue
+}
+
+type EnginePoolOptions struct {
+	// Number of engines kept in the pool.
+	//
+	// Zero means default.
+	Size int
+
+	// Options to create the engines.
+	Engine EasyEngineOptions
+
+	// If not nil, this function is used to create the engines instead of NewEasyEngine. The engine
+	// must be bound to ctx, i.e. cancelling it must terminate the engine.
+	NewEngine func(ctx context.Context) (*Engine, error)
+
+	// Options applied to each engine after it starts.
+	Options []OptionSetting
+
+	// Maximum time to wait until the engine starts and applies all the options.
+	//
+	// Zero means default.
+	StartTimeout time.Duration
+
+	// Maximum time to wait for the engine to reply to "isready" or to stop the search.
+	//
+	// Zero means default.
+	PingTimeout time.Duration
+
+	// Delay before the next attempt to restart the engine if the previous attempt failed.
+	//
+	// Zero means default.
+	RestartDelay time.Duration
+
+	// Interval between health checks of idle engines.
+	//
+	// Zero means default.
+	CheckInterval time.Duration
+
+	Logger Logger
+}
+
+func (o EnginePoolOptions) Clone() EnginePoolOptions {
+	o.Engine.Args = slices.Clone(o.Engine.Args)
+	o.Engine.Env = slices.Clone(o.Engine.Env)
+	o.Options = slices.Clone(o.Options)
+	return o
+}
+
+func (o *EnginePoolOptions) FillDefaults() {
+	if o.Size == 0 {
+		o.Size = 1
+	}
+	if o.StartTimeout == 0 {
+		o.StartTimeout = 10 * time.Second
+	}
+	if o.PingTimeout == 0 {
+		o.PingTimeout = 5 * time.Second
+	}
+	if o.RestartDelay == 0 {
+		o.RestartDelay = 1 * time.Second
+	}
+	if o.CheckInterval == 0 {
+		o.CheckInterval = 30 * time.Second
+	}
+	if o.Logger == nil {
+		o.Logger = NewNullLogger()
+	}
+}
+
+// EnginePool keeps a number of warm engines with the same configuration. The engines are taken via
+// Acquire() and must be returned back via Release() when they are not needed anymore. Returned
+// engines are checked for health and restarted if they crashed or hung.
+type EnginePool struct {
+	o EnginePoolOptions
+
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+	idle   chan *Engine
+
+	restarts atomic.Int64
+}
+
+func NewEnginePool(ctx context.Context, o EnginePoolOptions) (*EnginePool, error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if o.Size < 0 {
+		return nil, fmt.Errorf("negative pool size")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &EnginePool{
+		o:      o,
+		ctx:    ctx,
+		cancel: cancel,
+		idle:   make(chan *Engine, o.Size),
+	}
+	for i := range o.Size {
+		e, err := p.newEngine()
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("start engine #%v: %w", i+1, err)
+		}
+		p.idle <- e
+	}
+
+	p.wg.Add(1)
+	go p.checkLoop()
+
+	return p, nil
+}
+
+func (p *EnginePool) newEngine() (*Engine, error) {
+	var (
+		e   *Engine
+		err error
+	)
+	if p.o.NewEngine != nil {
+		e, err = p.o.NewEngine(p.ctx)
+	} else {
+		eo := p.o.Engine
+		eo.WaitInitialized = false
+		e, err = NewEasyEngine(p.ctx, eo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create engine: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.o.StartTimeout)
+	defer cancel()
+	if err := e.WaitInitialized(ctx); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("wait initialized: %w", err)
+	}
+	for _, opt := range p.o.Options {
+		if err := e.SetOption(ctx, opt.Name, opt.Value); err != nil {
+			e.Close()
+			return nil, fmt.Errorf("set option %q: %w", opt.Name, err)
+		}
+	}
+	if err := e.Ping(ctx); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+	return e, nil
+}
+
+func (p *EnginePool) checkEngine(e *Engine, reset bool) error {
+	if e.Terminated() {
+		return fmt.Errorf("engine terminated")
+	}
+	ctx, cancel := context.WithTimeout(p.ctx, p.o.PingTimeout)
+	defer cancel()
+	if s := e.CurSearch(); s != nil {
+		if err := s.Stop(ctx, true); err != nil {
+			return fmt.Errorf("stop search: %w", err)
+		}
+	}
+	if reset {
+		if err := e.UCINewGame(ctx, true); err != nil {
+			return fmt.Errorf("ucinewgame: %w", err)
+		}
+		return nil
+	}
+	if err := e.Ping(ctx); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
+
+// Replaces the engine with a fresh one in background.
+func (p *EnginePool) restart(old *Engine) {
+	p.restarts.Add(1)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if old != nil {
+			old.Close()
+		}
+		for {
+			if p.ctx.Err() != nil {
+				return
+			}
+			e, err := p.newEngine()
+			if err == nil {
+				p.putIdle(e)
+				return
+			}
+			p.o.Logger.Printf("pool: cannot restart engine: %v", err)
+			select {
+			case <-time.After(p.o.RestartDelay):
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (p *EnginePool) putIdle(e *Engine) {
+	if p.ctx.Err() != nil {
+		e.Close()
+		return
+	}
+	p.idle <- e
+}
+
+func (p *EnginePool) checkLoop() {
+	defer p.wg.Done()
+	t := time.NewTicker(p.o.CheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-p.ctx.Done():
+			return
+		}
+		for range len(p.idle) {
+			var e *Engine
+			select {
+			case e = <-p.idle:
+			default:
+			}
+			if e == nil {
+				break
+			}
+			if err := p.checkEngine(e, false); err != nil {
+				p.o.Logger.Printf("pool: idle engine is unhealthy: %v", err)
+				p.restart(e)
+				continue
+			}
+			p.putIdle(e)
+		}
+	}
+}
+
+// Acquire takes an engine from the pool, waiting until one is available.
+func (p *EnginePool) Acquire(ctx context.Context) (*Engine, error) {
+	for {
+		select {
+		case e := <-p.idle:
+			if e.Terminated() {
+				p.o.Logger.Printf("pool: idle engine terminated")
+				p.restart(e)
+				continue
+			}
+			return e, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait: %w", ctx.Err())
+		case <-p.ctx.Done():
+			return nil, fmt.Errorf("pool closed")
+		}
+	}
+}
+
+// Release returns the engine to the pool. The engine is verified with "ucinewgame" followed by
+// "isready" and restarted if it doesn't respond. The running search, if any, is stopped.
+//
+// Each engine obtained from Acquire() must be released exactly once and must not be used after
+// release.
+func (p *EnginePool) Release(e *Engine) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.checkEngine(e, true); err != nil {
+			if p.ctx.Err() != nil {
+				e.Close()
+				return
+			}
+			p.o.Logger.Printf("pool: released engine is unhealthy: %v", err)
+			p.restart(e)
+			return
+		}
+		p.putIdle(e)
+	}()
+}
+
+func (p *EnginePool) Size() int       { return p.o.Size }
+func (p *EnginePool) Restarts() int64 { return p.restarts.Load() }
+
+// Close terminates all the engines in the pool, including the acquired ones.
+func (p *EnginePool) Close() {
+	p.cancel()
+	p.wg.Wait()
+	for {
+		select {
+		case e := <-p.idle:
+			e.Close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package uci_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEngines struct {
+	mu      sync.Mutex
+	engines []*ucitest.Engine
+	hang    atomic.Pointer[ucitest.Engine]
+}
+
+func (f *fakeEngines) newEngine(ctx context.Context) (*uci.Engine, error) {
+	fe := ucitest.NewEngine(ucitest.EngineOptions{
+		Options: []string{"option name Hash type spin default 16 min 1 max 1024"},
+		Hook: func(e *ucitest.Engine, line string) bool {
+			return line == "isready" && f.hang.Load() == e
+		},
+	})
+	f.mu.Lock()
+	f.engines = append(f.engines, fe)
+	f.mu.Unlock()
+	return uci.NewEngine(ctx, fe, nil, uci.EngineOptions{}), nil
+}
+
+func (f *fakeEngines) last() *ucitest.Engine {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.engines[len(f.engines)-1]
+}
+
+func (f *fakeEngines) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.engines)
+}
+
+func TestEnginePool(t *testing.T) {
+	ctx := context.Background()
+	var f fakeEngines
+	p, err := uci.NewEnginePool(ctx, uci.EnginePoolOptions{
+		Size:         2,
+		NewEngine:    f.newEngine,
+		Options:      []uci.OptionSetting{{Name: "Hash", Value: uci.OptValueInt(64)}},
+		PingTimeout:  100 * time.Millisecond,
+		RestartDelay: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer p.Close()
+	require.Equal(t, 2, f.count())
+	for _, fe := range f.engines {
+		v, ok := fe.Option("Hash")
+		assert.True(t, ok)
+		assert.Equal(t, "64", v)
+	}
+
+	e1, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	e2, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, e1, e2)
+
+	tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = p.Acquire(tctx)
+	cancel()
+	assert.Error(t, err)
+
+	// Healthy engine goes back to the pool as is.
+	p.Release(e1)
+	e3, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	assert.Same(t, e1, e3)
+	assert.Equal(t, int64(0), p.Restarts())
+
+	// Crashed engine is restarted, and the options are re-applied.
+	f.engines[0].Kill()
+	p.Release(e3)
+	e4, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, e1, e4)
+	assert.Equal(t, int64(1), p.Restarts())
+	assert.Equal(t, 3, f.count())
+	v, ok := f.last().Option("Hash")
+	assert.True(t, ok)
+	assert.Equal(t, "64", v)
+
+	// Hung engine is restarted.
+	f.hang.Store(f.last())
+	p.Release(e4)
+	assert.Eventually(t, func() bool { return p.Restarts() == 2 }, time.Second, 10*time.Millisecond)
+	e5, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, e5.Terminated())
+	assert.Equal(t, 4, f.count())
+
+	p.Release(e2)
+	p.Release(e5)
+}
+
+func TestEnginePoolClose(t *testing.T) {
+	ctx := context.Background()
+	var f fakeEngines
+	p, err := uci.NewEnginePool(ctx, uci.EnginePoolOptions{
+		Size:      2,
+		NewEngine: f.newEngine,
+	})
+	require.NoError(t, err)
+	e, err := p.Acquire(ctx)
+	require.NoError(t, err)
+	p.Close()
+	assert.Eventually(t, e.Terminated, time.Second, 10*time.Millisecond)
+	for _, fe := range f.engines {
+		select {
+		case <-fe.Done():
+		case <-time.After(time.Second):
+			t.Fatal("engine is still running")
+		}
+	}
+	_, err = p.Acquire(ctx)
+	assert.Error(t, err)
+}
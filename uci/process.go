@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
@@ -45,6 +48,20 @@ func NewTracingProcess(p Process, l Logger, o TracingProcessOptions) Process {
 }
 
 func NewCmdProcess(cmd *exec.Cmd) (Process, error) {
+	return newCmdProcess(cmd, cmdHooks{})
+}
+
+// Hooks allow to customize how the process is started and stopped. All of them are optional.
+type cmdHooks struct {
+	// Replaces the default way of killing the process.
+	kill func(p *os.Process) error
+	// Called before waiting for the process. It must not reap the process.
+	beforeWait func(p *os.Process)
+	// Called after the process has terminated.
+	afterWait func()
+}
+
+func newCmdProcess(cmd *exec.Cmd, h cmdHooks) (Process, error) {
 	if cmd.Process != nil {
 		return nil, fmt.Errorf("process already started")
 	}
@@ -70,6 +87,7 @@ func NewCmdProcess(cmd *exec.Cmd) (Process, error) {
 
 	res := &cmdProcess{
 		c:    cmd,
+		h:    h,
 		done: make(chan struct{}),
 		err:  nil,
 
@@ -82,6 +100,14 @@ func NewCmdProcess(cmd *exec.Cmd) (Process, error) {
 	return res, nil
 }
 
+func NewConnProcess(conn net.Conn) Process {
+	return &connProcess{
+		conn:  conn,
+		bufIn: bufio.NewReader(conn),
+		done:  make(chan struct{}),
+	}
+}
+
 func NewCancellableProcess(ctx context.Context, p Process) Process {
 	return &cancellableProcess{ctx: ctx, p: p}
 }
@@ -93,9 +119,11 @@ type tracingProcess struct {
 }
 
 func (p *tracingProcess) Send(s string) error {
-	p.l.Printf("%v -> %v: %v", p.o.MyName, p.o.ProcessName, s)
+	r := LogRecord{Level: slog.LevelDebug, Engine: p.o.ProcessName, Dir: LogDirSend, Command: commandType(s)}
+	logRecord(p.l, r, "%v -> %v: %v", p.o.MyName, p.o.ProcessName, s)
 	if err := p.p.Send(s); err != nil {
-		p.l.Printf("%v: send failed: %v", p.o.ProcessName, err)
+		r.Level = slog.LevelError
+		logRecord(p.l, r, "%v: send failed: %v", p.o.ProcessName, err)
 		return err
 	}
 	return nil
@@ -105,11 +133,13 @@ func (p *tracingProcess) Recv() (string, error) {
 	s, err := p.p.Recv()
 	if err != nil {
 		if !errors.Is(err, io.EOF) {
-			p.l.Printf("%v: recv failed: %v", p.o.ProcessName, err)
+			r := LogRecord{Level: slog.LevelError, Engine: p.o.ProcessName, Dir: LogDirRecv}
+			logRecord(p.l, r, "%v: recv failed: %v", p.o.ProcessName, err)
 		}
 		return "", err
 	}
-	p.l.Printf("%v -> %v: %v", p.o.ProcessName, p.o.MyName, s)
+	r := LogRecord{Level: slog.LevelDebug, Engine: p.o.ProcessName, Dir: LogDirRecv, Command: commandType(s)}
+	logRecord(p.l, r, "%v -> %v: %v", p.o.ProcessName, p.o.MyName, s)
 	return s, nil
 }
 
@@ -117,12 +147,13 @@ func (p *tracingProcess) Done() <-chan struct{} { return p.p.Done() }
 func (p *tracingProcess) Err() error            { return p.p.Err() }
 
 func (p *tracingProcess) Kill() {
-	p.l.Printf("%v: killing", p.o.ProcessName)
+	logRecord(p.l, LogRecord{Level: slog.LevelInfo, Engine: p.o.ProcessName}, "%v: killing", p.o.ProcessName)
 	p.p.Kill()
 }
 
 type cmdProcess struct {
 	c *exec.Cmd
+	h cmdHooks
 
 	done chan struct{}
 	err  error
@@ -137,10 +168,16 @@ type cmdProcess struct {
 }
 
 func (p *cmdProcess) waitLoop() {
+	if p.h.beforeWait != nil {
+		p.h.beforeWait(p.c.Process)
+	}
 	err := p.c.Wait()
 	if err != nil {
 		err = fmt.Errorf("wait: %w", err)
 	}
+	if p.h.afterWait != nil {
+		p.h.afterWait()
+	}
 	p.err = err
 	close(p.done)
 	p.closed.Store(true)
@@ -196,12 +233,91 @@ func (p *cmdProcess) Err() error {
 
 func (p *cmdProcess) Kill() {
 	if !p.closed.Swap(true) {
-		_ = p.c.Process.Kill()
+		if p.h.kill != nil {
+			_ = p.h.kill(p.c.Process)
+		} else {
+			_ = p.c.Process.Kill()
+		}
 		_ = p.inPipe.Close()
 		_ = p.outPipe.Close()
 	}
 }
 
+type connProcess struct {
+	conn  net.Conn
+	bufIn *bufio.Reader
+
+	inMu  sync.Mutex
+	outMu sync.Mutex
+
+	done chan struct{}
+	once sync.Once
+	err  error
+}
+
+func (p *connProcess) finish(err error) {
+	p.once.Do(func() {
+		p.err = err
+		_ = p.conn.Close()
+		close(p.done)
+	})
+}
+
+func (p *connProcess) Send(s string) error {
+	select {
+	case <-p.done:
+		return fmt.Errorf("connection closed")
+	default:
+	}
+
+	p.outMu.Lock()
+	defer p.outMu.Unlock()
+	if _, err := io.WriteString(p.conn, s+"\n"); err != nil {
+		p.finish(fmt.Errorf("write: %w", err))
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+func (p *connProcess) Recv() (string, error) {
+	select {
+	case <-p.done:
+		return "", io.EOF
+	default:
+	}
+
+	p.inMu.Lock()
+	defer p.inMu.Unlock()
+	s, err := p.bufIn.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			p.finish(nil)
+			return "", io.EOF
+		}
+		p.finish(fmt.Errorf("read: %w", err))
+		return "", fmt.Errorf("read: %w", err)
+	}
+	s = strings.TrimRight(s, "\n\r")
+	return s, nil
+}
+
+func (p *connProcess) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *connProcess) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+func (p *connProcess) Kill() {
+	p.finish(nil)
+}
+
 type cancellableProcess struct {
 	ctx context.Context
 	p   Process
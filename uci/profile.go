@@ -0,0 +1,223 @@
+package uci
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OptionProfile maps option names to their values in the same form as they appear in "setoption".
+// Buttons are pressed if they are present in the profile, their values are ignored.
+type OptionProfile map[string]string
+
+func (p OptionProfile) Clone() OptionProfile {
+	if p == nil {
+		return nil
+	}
+	res := make(OptionProfile, len(p))
+	for k, v := range p {
+		res[k] = v
+	}
+	return res
+}
+
+type UnknownOptionError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownOptionError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown option %q", e.Name)
+	}
+	quoted := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		quoted[i] = strconv.Quote(s)
+	}
+	return fmt.Sprintf("unknown option %q (did you mean %v?)", e.Name, strings.Join(quoted, " or "))
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func suggestOptions(name string, names []string) []string {
+	const maxSuggestions = 3
+	type candidate struct {
+		name string
+		dist int
+	}
+	folded := caseFold(name)
+	limit := max(2, len(folded)/3)
+	var cs []candidate
+	for _, n := range names {
+		fn := caseFold(n)
+		d := levenshtein(folded, fn)
+		if strings.Contains(fn, folded) || strings.Contains(folded, fn) {
+			d = min(d, 1)
+		}
+		if d <= limit {
+			cs = append(cs, candidate{name: n, dist: d})
+		}
+	}
+	slices.SortFunc(cs, func(a, b candidate) int {
+		if c := cmp.Compare(a.dist, b.dist); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+	res := make([]string, 0, min(len(cs), maxSuggestions))
+	for i := 0; i < len(cs) && i < maxSuggestions; i++ {
+		res = append(res, cs[i].name)
+	}
+	return res
+}
+
+func parseOptValue(opt Option, s string, co coderOptions) (OptValue, error) {
+	var v OptValue
+	switch opt.(type) {
+	case *OptionCheck:
+		switch s {
+		case "true":
+			v = OptValueBool(true)
+		case "false":
+			v = OptValueBool(false)
+		default:
+			return nil, fmt.Errorf("bad boolean %q", s)
+		}
+	case *OptionSpin:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad integer %q", s)
+		}
+		v = OptValueInt(n)
+	case *OptionCombo, *OptionString:
+		v = OptValueString(s)
+	case *OptionButton:
+		v = OptValueButton{}
+	default:
+		panic("must not happen")
+	}
+	if err := opt.Clone().setValue(v, co); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ParseOptValue converts the string into the value suitable for the option name, according to the
+// option type advertised by the engine. The value is also validated, so it's guaranteed that
+// SetOption() will accept it.
+func (e *Engine) ParseOptValue(name, s string) (OptValue, error) {
+	opt := e.GetOpt(name)
+	if opt == nil {
+		if !e.Initialized() {
+			return nil, fmt.Errorf("engine not initialized")
+		}
+		return nil, &UnknownOptionError{Name: name, Suggestions: suggestOptions(name, e.ListOpts())}
+	}
+	v, err := parseOptValue(opt, s, e.o.coderOptions())
+	if err != nil {
+		return nil, fmt.Errorf("option %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// ConvertProfile converts all the values in the profile. All the errors are reported together. The
+// resulting settings are sorted by name.
+func (e *Engine) ConvertProfile(p OptionProfile) ([]OptionSetting, error) {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	res := make([]OptionSetting, 0, len(p))
+	for _, name := range names {
+		v, err := e.ParseOptValue(name, p[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res = append(res, OptionSetting{Name: name, Value: v})
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return res, nil
+}
+
+// ApplyProfile sets all the options from the profile. If any value is invalid, then nothing is
+// sent to the engine.
+func (e *Engine) ApplyProfile(ctx context.Context, p OptionProfile) error {
+	settings, err := e.ConvertProfile(p)
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if err := e.SetOption(ctx, s.Name, s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Profile exports the current values of all the options except buttons.
+func (e *Engine) Profile() OptionProfile {
+	res := make(OptionProfile)
+	for _, name := range e.ListOpts() {
+		opt := e.GetOpt(name)
+		if opt == nil {
+			continue
+		}
+		if _, ok := opt.(*OptionButton); ok {
+			continue
+		}
+		res[name] = opt.Value().serialize()
+	}
+	return res
+}
+
+type OptionDiff struct {
+	Name    string
+	Default OptValue
+	Current OptValue
+}
+
+// DiffOptions returns the options whose current values differ from the defaults advertised by the
+// engine. The result is sorted by name.
+func (e *Engine) DiffOptions() []OptionDiff {
+	var res []OptionDiff
+	for _, name := range e.ListOpts() {
+		opt := e.GetOpt(name)
+		def := e.GetOptDefault(name)
+		if opt == nil || def == nil {
+			continue
+		}
+		if cur := opt.Value(); cur != def {
+			res = append(res, OptionDiff{Name: name, Default: def, Current: cur})
+		}
+	}
+	slices.SortFunc(res, func(a, b OptionDiff) int { return cmp.Compare(a.Name, b.Name) })
+	return res
+}
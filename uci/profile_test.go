@@ -0,0 +1,84 @@
+package uci_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionProfile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fe := ucitest.NewEngine(ucitest.EngineOptions{
+		Options: []string{
+			"option name Hash type spin default 16 min 1 max 1024",
+			"option name Threads type spin default 1 min 1 max 64",
+			"option name UCI_ShowWDL type check default false",
+			"option name Style type combo default Solid var Solid var Risky",
+			"option name SyzygyPath type string default <empty>",
+			"option name Clear Hash type button",
+		},
+	})
+	e := uci.NewEngine(ctx, fe, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+
+	v, err := e.ParseOptValue("hash", "128")
+	require.NoError(t, err)
+	assert.Equal(t, uci.OptValueInt(128), v)
+	_, err = e.ParseOptValue("Hash", "4096")
+	assert.Error(t, err)
+	_, err = e.ParseOptValue("UCI_ShowWDL", "yes")
+	assert.Error(t, err)
+
+	_, err = e.ParseOptValue("Hsah", "1")
+	var unk *uci.UnknownOptionError
+	require.ErrorAs(t, err, &unk)
+	assert.Equal(t, []string{"Hash"}, unk.Suggestions)
+
+	// Nothing is sent if at least one value is bad, and all the problems are reported.
+	err = e.ApplyProfile(ctx, uci.OptionProfile{
+		"Hash":    "64",
+		"Threads": "100",
+		"Style":   "Wild",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Threads")
+	assert.Contains(t, err.Error(), "Style")
+	require.NoError(t, e.Ping(ctx))
+	assert.Equal(t, []string{"uci", "isready"}, fe.Received())
+
+	require.NoError(t, e.ApplyProfile(ctx, uci.OptionProfile{
+		"Hash":       "64",
+		"Style":      "risky",
+		"Clear Hash": "",
+	}))
+	require.NoError(t, e.Ping(ctx))
+	hash, ok := fe.Option("Hash")
+	assert.True(t, ok)
+	assert.Equal(t, "64", hash)
+
+	assert.Equal(t, uci.OptionProfile{
+		"Hash":        "64",
+		"Threads":     "1",
+		"UCI_ShowWDL": "false",
+		"Style":       "Risky",
+		"SyzygyPath":  "",
+	}, e.Profile())
+	assert.Equal(t, []uci.OptionDiff{
+		{Name: "Hash", Default: uci.OptValueInt(16), Current: uci.OptValueInt(64)},
+		{Name: "Style", Default: uci.OptValueString("Solid"), Current: uci.OptValueString("Risky")},
+	}, e.DiffOptions())
+	assert.Equal(t, uci.OptValueInt(16), e.GetOptDefault("HASH"))
+
+	err = e.SetOption(ctx, "Threads", uci.OptValueInt(0))
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &unk))
+}
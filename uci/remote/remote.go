@@ -0,0 +1,92 @@
+// This package allows to run UCI engines on remote machines. The server exposes a local engine
+// executable on a socket, and the client connects to it, obtaining uci.Process.
+//
+// Before the UCI traffic starts, the client sends "auth <token>" line, and the server replies
+// either with "ok" or with "error <message>". After that, each connection becomes a separate
+// engine session, i.e. the server starts a fresh engine process for every accepted connection.
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+const (
+	authCmd   = "auth"
+	authOk    = "ok"
+	authError = "error"
+)
+
+type DialOptions struct {
+	Token string
+
+	// Maximum time to wait for the server to accept the authentication.
+	//
+	// Zero means default.
+	AuthTimeout time.Duration
+}
+
+func (o *DialOptions) FillDefaults() {
+	if o.AuthTimeout == 0 {
+		o.AuthTimeout = 5 * time.Second
+	}
+}
+
+func (o DialOptions) Clone() DialOptions {
+	return o
+}
+
+// Dial connects to the server and authenticates. Network and address have the same meaning as in
+// net.Dial, e.g. ("tcp", "example.com:1234") or ("unix", "/run/engine.sock").
+func Dial(ctx context.Context, network, addr string, o DialOptions) (uci.Process, error) {
+	o = o.Clone()
+	o.FillDefaults()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	p, err := NewClientProcess(conn, o)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewClientProcess authenticates on an already established connection.
+func NewClientProcess(conn net.Conn, o DialOptions) (uci.Process, error) {
+	o = o.Clone()
+	o.FillDefaults()
+
+	if strings.ContainsAny(o.Token, " \t\r\n") {
+		return nil, fmt.Errorf("token must not contain whitespace")
+	}
+	if err := conn.SetDeadline(time.Now().Add(o.AuthTimeout)); err != nil {
+		return nil, fmt.Errorf("set deadline: %w", err)
+	}
+	p := uci.NewConnProcess(conn)
+	if err := p.Send(authCmd + " " + o.Token); err != nil {
+		return nil, fmt.Errorf("send auth: %w", err)
+	}
+	reply, err := p.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("recv auth reply: %w", err)
+	}
+	if reply != authOk {
+		msg, _ := strings.CutPrefix(reply, authError+" ")
+		p.Kill()
+		return nil, fmt.Errorf("auth rejected: %v", msg)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		p.Kill()
+		return nil, fmt.Errorf("reset deadline: %w", err)
+	}
+	return p, nil
+}
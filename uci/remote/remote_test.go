@@ -0,0 +1,106 @@
+package remote
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T, network, addr string, o ServerOptions) (*Server, string) {
+	l, err := net.Listen(network, addr)
+	require.NoError(t, err)
+	if o.NewProcess == nil {
+		o.NewProcess = func() (uci.Process, error) {
+			return ucitest.NewProcess(ucitest.EngineOptions{Name: "Remote"}), nil
+		}
+	}
+	srv, err := NewServer(o)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, srv.Serve(ctx, l))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return srv, l.Addr().String()
+}
+
+func playOneMove(t *testing.T, p uci.Process) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	e := uci.NewEngine(ctx, p, nil, uci.EngineOptions{})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+	info, ok := e.Info()
+	require.True(t, ok)
+	assert.Equal(t, "Remote", info.Name)
+
+	require.NoError(t, e.SetPosition(ctx, chess.NewGame()))
+	s, err := e.Go(ctx, uci.GoOptions{Depth: maybe.Some[int64](1)}, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Wait(ctx))
+	_, err = s.BestMove()
+	require.NoError(t, err)
+	require.NoError(t, e.Quit(ctx, true))
+}
+
+func TestTCP(t *testing.T) {
+	_, addr := startServer(t, "tcp", "127.0.0.1:0", ServerOptions{Token: "secret"})
+	p, err := Dial(context.Background(), "tcp", addr, DialOptions{Token: "secret"})
+	require.NoError(t, err)
+	playOneMove(t, p)
+}
+
+func TestUnix(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "engine.sock")
+	_, addr := startServer(t, "unix", sock, ServerOptions{Token: "secret"})
+	p, err := Dial(context.Background(), "unix", addr, DialOptions{Token: "secret"})
+	require.NoError(t, err)
+	playOneMove(t, p)
+}
+
+func TestBadToken(t *testing.T) {
+	_, addr := startServer(t, "tcp", "127.0.0.1:0", ServerOptions{Token: "secret"})
+	_, err := Dial(context.Background(), "tcp", addr, DialOptions{Token: "wrong"})
+	assert.ErrorContains(t, err, "authentication failed")
+}
+
+func TestMaxSessions(t *testing.T) {
+	srv, addr := startServer(t, "tcp", "127.0.0.1:0", ServerOptions{MaxSessions: 1, InsecureNoAuth: true})
+	p, err := Dial(context.Background(), "tcp", addr, DialOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.Sessions())
+	_, err = Dial(context.Background(), "tcp", addr, DialOptions{})
+	assert.ErrorContains(t, err, "too many sessions")
+	p.Kill()
+	assert.Eventually(t, func() bool { return srv.Sessions() == 0 }, time.Second, 10*time.Millisecond)
+	p, err = Dial(context.Background(), "tcp", addr, DialOptions{})
+	require.NoError(t, err)
+	playOneMove(t, p)
+}
+
+func TestNoToken(t *testing.T) {
+	_, err := NewServer(ServerOptions{Name: "engine"})
+	assert.ErrorContains(t, err, "empty token")
+}
+
+func TestLongAuthLine(t *testing.T) {
+	_, addr := startServer(t, "tcp", "127.0.0.1:0", ServerOptions{Token: "secret"})
+	_, err := Dial(context.Background(), "tcp", addr, DialOptions{Token: strings.Repeat("a", 10000)})
+	assert.ErrorContains(t, err, "authentication failed")
+}
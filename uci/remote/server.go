@@ -0,0 +1,262 @@
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alex65536/go-chess/uci"
+)
+
+const maxAuthLine = 4096
+
+type ServerOptions struct {
+	// Token which the clients must provide. It must not be empty unless InsecureNoAuth is set.
+	Token string
+
+	// Accept any client without checking the token. Use it only if the socket is protected by
+	// other means, e.g. it's a Unix socket with restricted permissions.
+	InsecureNoAuth bool
+
+	// Engine executable to run for each session.
+	Name string
+	Args []string
+	Env  []string
+	Dir  string
+
+	// If not nil, this function is used to start the engine instead of running the executable.
+	NewProcess func() (uci.Process, error)
+
+	// Maximum number of simultaneous sessions.
+	//
+	// Zero means no limit.
+	MaxSessions int
+
+	// Maximum time to wait for the client to authenticate.
+	//
+	// Zero means default.
+	AuthTimeout time.Duration
+
+	Logger uci.Logger
+}
+
+func (o ServerOptions) Clone() ServerOptions {
+	o.Args = slices.Clone(o.Args)
+	o.Env = slices.Clone(o.Env)
+	return o
+}
+
+func (o *ServerOptions) FillDefaults() {
+	if o.AuthTimeout == 0 {
+		o.AuthTimeout = 5 * time.Second
+	}
+	if o.Logger == nil {
+		o.Logger = uci.NewNullLogger()
+	}
+	if o.NewProcess == nil {
+		name, args, env, dir := o.Name, slices.Clone(o.Args), slices.Clone(o.Env), o.Dir
+		o.NewProcess = func() (uci.Process, error) {
+			cmd := exec.Command(name, args...)
+			cmd.Env = env
+			cmd.Dir = dir
+			return uci.NewCmdProcess(cmd)
+		}
+	}
+}
+
+func (o *ServerOptions) Validate() error {
+	if o.Token == "" && !o.InsecureNoAuth {
+		return fmt.Errorf("empty token")
+	}
+	if o.MaxSessions < 0 {
+		return fmt.Errorf("negative max sessions")
+	}
+	if o.AuthTimeout < 0 {
+		return fmt.Errorf("negative auth timeout")
+	}
+	return nil
+}
+
+type Server struct {
+	o ServerOptions
+
+	mu       sync.Mutex
+	sessions int
+}
+
+func NewServer(o ServerOptions) (*Server, error) {
+	o = o.Clone()
+	o.FillDefaults()
+	if err := o.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return &Server{o: o}, nil
+}
+
+func (s *Server) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions
+}
+
+func (s *Server) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.o.MaxSessions != 0 && s.sessions >= s.o.MaxSessions {
+		return false
+	}
+	s.sessions++
+	return true
+}
+
+func (s *Server) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions--
+}
+
+// Serve accepts the connections from l until ctx is cancelled or the listener fails. When Serve
+// returns, the listener is closed and all the sessions are terminated.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// Reads the authentication line directly from conn. The line is read byte by byte, so nothing
+// after it is consumed, and its length is limited, so the unauthenticated clients cannot make us
+// allocate much memory.
+func readAuthLine(conn net.Conn) (string, error) {
+	r := io.LimitReader(conn, maxAuthLine)
+	var b strings.Builder
+	var buf [1]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			if errors.Is(err, io.EOF) && b.Len() >= maxAuthLine {
+				return "", fmt.Errorf("auth line too long")
+			}
+			return "", err
+		}
+		if buf[0] == '\n' {
+			return strings.TrimRight(b.String(), "\r"), nil
+		}
+		_ = b.WriteByte(buf[0])
+	}
+}
+
+func (s *Server) authenticate(conn net.Conn) error {
+	line, err := readAuthLine(conn)
+	if err != nil {
+		return fmt.Errorf("recv auth: %w", err)
+	}
+	token, ok := strings.CutPrefix(line, authCmd+" ")
+	if !ok && line == authCmd {
+		token, ok = "", true
+	}
+	if !ok {
+		return fmt.Errorf("bad auth line")
+	}
+	if !s.o.InsecureNoAuth && subtle.ConstantTimeCompare([]byte(token), []byte(s.o.Token)) != 1 {
+		return fmt.Errorf("bad token")
+	}
+	return nil
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	addr := conn.RemoteAddr()
+	client := uci.NewConnProcess(conn)
+	defer client.Kill()
+
+	if err := conn.SetDeadline(time.Now().Add(s.o.AuthTimeout)); err != nil {
+		s.o.Logger.Printf("%v: set deadline: %v", addr, err)
+		return
+	}
+	if err := s.authenticate(conn); err != nil {
+		s.o.Logger.Printf("%v: authentication failed: %v", addr, err)
+		_ = client.Send(authError + " authentication failed")
+		return
+	}
+	if !s.acquire() {
+		s.o.Logger.Printf("%v: too many sessions", addr)
+		_ = client.Send(authError + " too many sessions")
+		return
+	}
+	defer s.release()
+
+	engine, err := s.o.NewProcess()
+	if err != nil {
+		s.o.Logger.Printf("%v: cannot start engine: %v", addr, err)
+		_ = client.Send(authError + " cannot start engine")
+		return
+	}
+	defer engine.Kill()
+
+	if err := client.Send(authOk); err != nil {
+		s.o.Logger.Printf("%v: send auth reply: %v", addr, err)
+		return
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		s.o.Logger.Printf("%v: reset deadline: %v", addr, err)
+		return
+	}
+
+	s.o.Logger.Printf("%v: session started", addr)
+	defer s.o.Logger.Printf("%v: session finished", addr)
+
+	done := make(chan struct{}, 2)
+	forward := func(from, to uci.Process) {
+		defer func() { done <- struct{}{} }()
+		for {
+			ln, err := from.Recv()
+			if err != nil {
+				return
+			}
+			if err := to.Send(ln); err != nil {
+				return
+			}
+		}
+	}
+	go forward(client, engine)
+	go forward(engine, client)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	engine.Kill()
+	client.Kill()
+	<-done
+}
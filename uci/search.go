@@ -3,6 +3,7 @@ package uci
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"slices"
 	"sync"
@@ -119,8 +120,9 @@ func (s SearchStatus) Clone() SearchStatus {
 }
 
 type searchState struct {
-	c searchInfoConsumer
-	l Logger
+	c  searchInfoConsumer
+	l  Logger
+	id uint64
 
 	mu       sync.RWMutex
 	done     chan struct{}
@@ -132,28 +134,50 @@ type searchState struct {
 	best     []chess.Move
 	start    time.Time
 	b        *chess.Board
+	t        SearchTiming
 }
 
-func newSearchState(c searchInfoConsumer, l Logger, b *chess.Board, ponder bool) *searchState {
+func newSearchState(c searchInfoConsumer, l Logger, id uint64, b *chess.Board, opts GoOptions) *searchState {
+	var t SearchTiming
+	t.Movetime = opts.Movetime
+	if ts, ok := opts.TimeSpec.TryGet(); ok {
+		if b.Side() == chess.ColorWhite {
+			t.TimeLeft = maybe.Some(ts.Wtime)
+		} else {
+			t.TimeLeft = maybe.Some(ts.Btime)
+		}
+	}
 	return &searchState{
-		c: c,
-		l: l,
+		c:  c,
+		l:  withRecord(l, LogRecord{SearchID: id}),
+		id: id,
 
 		done:     make(chan struct{}),
 		err:      nil,
 		s:        SearchStatus{},
-		ponder:   ponder,
+		ponder:   opts.Ponder,
 		stopped:  false,
 		stopping: false,
 		best:     nil,
 		start:    time.Now(),
 		b:        b.Clone(),
+		t:        t,
 	}
 }
 
 func (s *searchState) OnInfo(info Info, strOnly bool) error {
 	defer func() { s.c(s, info) }()
 
+	now := time.Now()
+	s.mu.Lock()
+	if s.t.FirstInfo.IsZero() {
+		s.t.FirstInfo = now
+	}
+	if t, ok := info.Time.TryGet(); ok {
+		s.t.ReportedTime = maybe.Some(t)
+	}
+	s.mu.Unlock()
+
 	if strOnly {
 		// Info contains only string, nothing to handle.
 		return nil
@@ -233,11 +257,13 @@ func (s *searchState) doStop(err error) {
 }
 
 func (s *searchState) OnBestMove(best chess.UCIMove, ponder maybe.Maybe[chess.UCIMove]) {
+	now := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.stopped {
 		panic("must not happen")
 	}
+	s.t.BestMove = now
 
 	var retErr error
 	defer func() {
@@ -245,7 +271,8 @@ func (s *searchState) OnBestMove(best chess.UCIMove, ponder maybe.Maybe[chess.UC
 			retErr = errors.Join(retErr, fmt.Errorf("search stopped by engine during ponder"))
 		}
 		if retErr != nil {
-			s.l.Printf("process \"bestmove\": %v", retErr)
+			r := LogRecord{Level: slog.LevelWarn, Dir: LogDirRecv, Command: "bestmove"}
+			logRecord(s.l, r, "process \"bestmove\": %v", retErr)
 		}
 		s.doStop(retErr)
 	}()
@@ -278,6 +305,29 @@ func (s *searchState) Cancel(err error) {
 	s.doStop(err)
 }
 
+func (s *searchState) OnSent(cmd command, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch cmd.(type) {
+	case cmdGo:
+		s.t.GoSent = at
+	case cmdStop:
+		s.t.StopSent = at
+	case cmdPonderHit:
+		s.t.PonderHitSent = at
+	}
+}
+
+func (s *searchState) Timing() SearchTiming {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Clone()
+}
+
+func (s *searchState) ID() uint64 {
+	return s.id
+}
+
 func (s *searchState) Done() <-chan struct{} {
 	return s.done
 }
@@ -4,8 +4,10 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/alex65536/go-chess/chess"
 	"github.com/alex65536/go-chess/util/maybe"
@@ -27,6 +29,8 @@ type engineState struct {
 	exiting bool
 	exited  bool
 	search  *searchState
+	nextID  uint64
+	timing  TimingStats
 	pongs   *list.List
 	board   *chess.Board
 	info    EngineInfo
@@ -45,6 +49,7 @@ func newEngineState(o EngineOptions, l Logger) *engineState {
 		exiting: false,
 		exited:  false,
 		search:  nil,
+		nextID:  1,
 		pongs:   list.New(),
 		board:   nil,
 		info:    EngineInfo{},
@@ -89,7 +94,7 @@ func (s *engineState) ProcessCommand(scmd command) (command, any, error) {
 			return nil, nil, fmt.Errorf("unknown option %q", cmd.name)
 		}
 		if err := opt.value.setValue(cmd.value, s.o.coderOptions()); err != nil {
-			return nil, nil, fmt.Errorf("set option %q", cmd.name)
+			return nil, nil, fmt.Errorf("set option %q: %w", cmd.name, err)
 		}
 		cmd = cmdSetOption{
 			name:  opt.name,
@@ -121,7 +126,8 @@ func (s *engineState) ProcessCommand(scmd command) (command, any, error) {
 		if err := cmd.opts.Validate(s.board); err != nil {
 			return nil, nil, fmt.Errorf("invalid options: %w", err)
 		}
-		s.search = newSearchState(cmd.c, s.l, s.board, cmd.opts.Ponder)
+		s.search = newSearchState(cmd.c, s.l, s.nextID, s.board, cmd.opts)
+		s.nextID++
 		return cmd, cmdGoRes(s.search), nil
 	case cmdStop:
 		if cmd.s == nil {
@@ -181,18 +187,19 @@ restart:
 		}
 	case "uciok":
 		if tok.More() {
-			s.l.Printf("parse \"uciok\": extra data")
+			s.msgLogger("uciok").Printf("parse \"uciok\": extra data")
 		}
 		return s.onUCIOk()
 	case "readyok":
 		if tok.More() {
-			s.l.Printf("parse \"readyok\": extra data")
+			s.msgLogger("readyok").Printf("parse \"readyok\": extra data")
 		}
 		return s.onReadyOk()
 	case "bestmove":
-		ms, err := parseBestMove(tok, s.l)
+		l := s.msgLogger("bestmove")
+		ms, err := parseBestMove(tok, l)
 		if err != nil {
-			s.l.Printf("parse \"bestmove\": %v", err)
+			l.Printf("parse \"bestmove\": %v", err)
 			return s.onSearchCancel(fmt.Errorf("parse: %w", err))
 		}
 		ponder := maybe.None[chess.UCIMove]()
@@ -205,13 +212,13 @@ restart:
 	case "registration":
 		return fmt.Errorf("\"registration\" not implemented")
 	case "info":
-		i, err := parseInfo(tok, s.l)
+		i, err := parseInfo(tok, s.msgLogger("info"))
 		if err != nil {
 			return fmt.Errorf("parse \"info\": %v", err)
 		}
 		return s.onInfo(i)
 	case "option":
-		o, err := parseOption(tok, s.l)
+		o, err := parseOption(tok, s.msgLogger("option"))
 		if err != nil {
 			return fmt.Errorf("parse \"option\": %v", err)
 		}
@@ -221,6 +228,25 @@ restart:
 	}
 }
 
+func (s *engineState) curSearchID() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.search == nil {
+		return 0
+	}
+	return s.search.ID()
+}
+
+// Returns the logger for the problems found while parsing the message from the engine.
+func (s *engineState) msgLogger(cmd string) Logger {
+	return withRecord(s.l, LogRecord{
+		Level:    slog.LevelWarn,
+		Dir:      LogDirRecv,
+		Command:  cmd,
+		SearchID: s.curSearchID(),
+	})
+}
+
 func (s *engineState) Finish() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -293,6 +319,7 @@ func (s *engineState) onOption(o optPair) error {
 	if _, ok := s.opts[folded]; ok {
 		return fmt.Errorf("duplicate option %q", o.name)
 	}
+	o.def = o.value.Value()
 	s.opts[folded] = o
 	return nil
 }
@@ -300,7 +327,8 @@ func (s *engineState) onOption(o optPair) error {
 func (s *engineState) onInfo(info Info) error {
 	strOnly := reflect.DeepEqual(info, Info{String: info.String})
 	if str, ok := info.String.TryGet(); ok && s.o.LogEngineString {
-		s.l.Printf("engine: %v", str)
+		r := LogRecord{Level: slog.LevelInfo, Dir: LogDirRecv, Command: "info", SearchID: s.curSearchID()}
+		logRecord(s.l, r, "engine: %v", str)
 	}
 
 	search, err := func() (*searchState, error) {
@@ -342,10 +370,33 @@ func (s *engineState) onBestMove(best chess.UCIMove, ponder maybe.Maybe[chess.UC
 		return fmt.Errorf("no search in progress")
 	}
 	s.search.OnBestMove(best, ponder)
+	if s.search.Err() == nil {
+		s.timing.add(s.search.Timing(), s.o.NearDeadline)
+	}
 	s.search = nil
 	return nil
 }
 
+func (s *engineState) CommandSent(cmd command, at time.Time) {
+	switch cmd.(type) {
+	case cmdGo, cmdStop, cmdPonderHit:
+	default:
+		return
+	}
+	s.mu.RLock()
+	search := s.search
+	s.mu.RUnlock()
+	if search != nil {
+		search.OnSent(cmd, at)
+	}
+}
+
+func (s *engineState) TimingStats() TimingStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.timing.Clone()
+}
+
 func (s *engineState) doPonderEnabled() bool {
 	if !s.inited {
 		return false
@@ -403,6 +454,19 @@ func (s *engineState) GetOpt(name string) Option {
 	return opt.value.Clone()
 }
 
+func (s *engineState) GetOptDefault(name string) OptValue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.inited {
+		return nil
+	}
+	opt, ok := s.opts[caseFold(name)]
+	if !ok {
+		return nil
+	}
+	return opt.def
+}
+
 func (s *engineState) ListOpts() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
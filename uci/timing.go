@@ -0,0 +1,145 @@
+package uci
+
+import (
+	"time"
+
+	"github.com/alex65536/go-chess/util/maybe"
+)
+
+// SearchTiming contains the timestamps of the search events, as observed by us. Zero timestamp
+// means that the event has not happened.
+type SearchTiming struct {
+	GoSent        time.Time
+	PonderHitSent time.Time
+	FirstInfo     time.Time
+	StopSent      time.Time
+	BestMove      time.Time
+
+	// Time reported by the engine in the last "info" message.
+	ReportedTime maybe.Maybe[time.Duration]
+
+	// Time limits for the search, as given in "go".
+	Movetime maybe.Maybe[time.Duration]
+	TimeLeft maybe.Maybe[time.Duration]
+}
+
+func (t SearchTiming) Clone() SearchTiming {
+	return t
+}
+
+// Start returns the moment from which the time is counted, i.e. either "go" or "ponderhit".
+func (t *SearchTiming) Start() time.Time {
+	if !t.PonderHitSent.IsZero() {
+		return t.PonderHitSent
+	}
+	return t.GoSent
+}
+
+// Elapsed returns the time spent on the search, measured by us. Pondering before "ponderhit" is not
+// counted.
+func (t *SearchTiming) Elapsed() maybe.Maybe[time.Duration] {
+	start := t.Start()
+	if start.IsZero() || t.BestMove.IsZero() {
+		return maybe.None[time.Duration]()
+	}
+	return maybe.Some(t.BestMove.Sub(start))
+}
+
+func (t *SearchTiming) StopLatency() maybe.Maybe[time.Duration] {
+	if t.StopSent.IsZero() || t.BestMove.IsZero() {
+		return maybe.None[time.Duration]()
+	}
+	return maybe.Some(t.BestMove.Sub(t.StopSent))
+}
+
+// Slack returns the time which would remain on the clock after the search. Negative value means
+// that the engine lost on time.
+func (t *SearchTiming) Slack() maybe.Maybe[time.Duration] {
+	left, ok := t.TimeLeft.TryGet()
+	if !ok {
+		return maybe.None[time.Duration]()
+	}
+	elapsed, ok := t.Elapsed().TryGet()
+	if !ok {
+		return maybe.None[time.Duration]()
+	}
+	return maybe.Some(left - elapsed)
+}
+
+// DurationStats aggregates a series of durations.
+type DurationStats struct {
+	Count int64
+	Total time.Duration
+	Min   time.Duration
+	Max   time.Duration
+}
+
+func (s *DurationStats) Add(d time.Duration) {
+	if s.Count == 0 || d < s.Min {
+		s.Min = d
+	}
+	if s.Count == 0 || d > s.Max {
+		s.Max = d
+	}
+	s.Count++
+	s.Total += d
+}
+
+func (s *DurationStats) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// TimingStats aggregates timings of all the searches finished with "bestmove".
+type TimingStats struct {
+	Searches int64
+
+	// Time spent above "movetime", for searches with "movetime".
+	Overhead DurationStats
+
+	// Time between "stop" and "bestmove".
+	StopLatency DurationStats
+
+	// Time which remained on the clock after the search, for searches with time control.
+	Slack DurationStats
+
+	// Number of searches which left less than EngineOptions.NearDeadline on the clock.
+	NearDeadline int64
+
+	// Number of searches which would have lost on time.
+	TimeLosses int64
+
+	// Time measured by us minus the time reported by engine in "info".
+	ReportDiff DurationStats
+}
+
+func (s TimingStats) Clone() TimingStats {
+	return s
+}
+
+func (s *TimingStats) add(t SearchTiming, nearDeadline time.Duration) {
+	elapsed, ok := t.Elapsed().TryGet()
+	if !ok {
+		return
+	}
+	s.Searches++
+	if mt, ok := t.Movetime.TryGet(); ok {
+		s.Overhead.Add(elapsed - mt)
+	}
+	if l, ok := t.StopLatency().TryGet(); ok {
+		s.StopLatency.Add(l)
+	}
+	if slack, ok := t.Slack().TryGet(); ok {
+		s.Slack.Add(slack)
+		if slack < 0 {
+			s.TimeLosses++
+		} else if slack < nearDeadline {
+			s.NearDeadline++
+		}
+	}
+	if r, ok := t.ReportedTime.TryGet(); ok {
+		s.ReportDiff.Add(t.BestMove.Sub(t.GoSent) - r)
+	}
+}
@@ -0,0 +1,67 @@
+package uci_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/clock"
+	"github.com/alex65536/go-chess/uci"
+	"github.com/alex65536/go-chess/uci/ucitest"
+	"github.com/alex65536/go-chess/util/maybe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTiming(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fe := ucitest.NewEngine(ucitest.EngineOptions{})
+	e := uci.NewEngine(ctx, fe, nil, uci.EngineOptions{NearDeadline: time.Hour})
+	defer e.Close()
+	require.NoError(t, e.WaitInitialized(ctx))
+	require.NoError(t, e.SetPosition(ctx, chess.NewGame()))
+
+	search := func(opts uci.GoOptions) *uci.Search {
+		s, err := e.Go(ctx, opts, nil)
+		require.NoError(t, err)
+		return s
+	}
+
+	s := search(uci.GoOptions{Movetime: maybe.Some(time.Minute)})
+	require.NoError(t, s.Wait(ctx))
+	tm := s.Timing()
+	assert.False(t, tm.GoSent.IsZero())
+	assert.False(t, tm.FirstInfo.Before(tm.GoSent))
+	assert.False(t, tm.BestMove.Before(tm.FirstInfo))
+	assert.True(t, tm.StopSent.IsZero())
+	// The fake engine always reports zero time.
+	assert.Equal(t, maybe.Some(time.Duration(0)), tm.ReportedTime)
+	assert.Equal(t, maybe.Some(time.Minute), tm.Movetime)
+
+	s = search(uci.GoOptions{TimeSpec: maybe.Some(clock.UCITimeSpec{
+		Wtime: 10 * time.Second,
+		Btime: 20 * time.Second,
+	})})
+	require.NoError(t, s.Wait(ctx))
+	assert.Equal(t, maybe.Some(10*time.Second), s.Timing().TimeLeft)
+
+	s = search(uci.GoOptions{Infinite: true})
+	require.NoError(t, s.Stop(ctx, true))
+	tm = s.Timing()
+	assert.False(t, tm.StopSent.IsZero())
+	assert.True(t, tm.StopLatency().IsSome())
+
+	st := e.TimingStats()
+	assert.Equal(t, int64(3), st.Searches)
+	assert.Equal(t, int64(1), st.Overhead.Count)
+	assert.Less(t, st.Overhead.Max, time.Duration(0))
+	assert.Equal(t, int64(1), st.StopLatency.Count)
+	assert.Equal(t, int64(1), st.Slack.Count)
+	assert.Equal(t, int64(1), st.NearDeadline)
+	assert.Equal(t, int64(0), st.TimeLosses)
+	assert.Equal(t, int64(3), st.ReportDiff.Count)
+	assert.GreaterOrEqual(t, st.ReportDiff.Min, time.Duration(0))
+}
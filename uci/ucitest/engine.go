@@ -0,0 +1,452 @@
+// This package implements a tiny in-process UCI engine, which is useful to test the code built on
+// top of the uci package without running real engine executables.
+package ucitest
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alex65536/go-chess/chess"
+	"github.com/alex65536/go-chess/uci"
+)
+
+type EngineOptions struct {
+	Name   string
+	Author string
+
+	// Raw "option" lines advertised by the engine before "uciok".
+	Options []string
+
+	// Chooses the best move. Only moves from the candidates are allowed to be returned. If nil,
+	// the first candidate is chosen.
+	Choose func(b *chess.Board, candidates []chess.Move) chess.Move
+
+	// Evaluates the position from the side to move's point of view. If nil, the material balance
+	// in centipawns is used.
+	Eval func(b *chess.Board) uci.Score
+
+	// Returns the candidate lines ordered from best to worst. If set, it is used instead of Choose
+	// and Eval, and the engine reports up to "MultiPV" option value lines.
+	Lines func(b *chess.Board, candidates []chess.Move) []Line
+
+	// Called for each line received from the client before the default processing. If it returns
+	// true, then the line is considered handled and the default processing is skipped.
+	Hook func(e *Engine, line string) bool
+}
+
+func (o *EngineOptions) FillDefaults() {
+	if o.Name == "" {
+		o.Name = "ucitest"
+	}
+	if o.Author == "" {
+		o.Author = "go-chess"
+	}
+	if o.Choose == nil {
+		o.Choose = func(_ *chess.Board, candidates []chess.Move) chess.Move {
+			return candidates[0]
+		}
+	}
+	if o.Eval == nil {
+		o.Eval = Material
+	}
+}
+
+func (o EngineOptions) Clone() EngineOptions {
+	o.Options = append([]string(nil), o.Options...)
+	return o
+}
+
+// Line is a candidate move together with its score from the side to move's point of view.
+type Line struct {
+	Move  chess.Move
+	Score uci.Score
+}
+
+type pendingSearch struct {
+	ponder   bool
+	infinite bool
+	best     chess.Move
+	ponderMv chess.Move
+	hasReply bool
+	info     []string
+}
+
+type Engine struct {
+	o EngineOptions
+
+	in   chan string
+	out  chan string
+	done chan struct{}
+	once sync.Once
+
+	mu      sync.Mutex
+	board   *chess.Board
+	opts    map[string]string
+	pending *pendingSearch
+	lines   []string
+}
+
+func NewEngine(o EngineOptions) *Engine {
+	o = o.Clone()
+	o.FillDefaults()
+	e := &Engine{
+		o:     o,
+		in:    make(chan string),
+		out:   make(chan string, 4096),
+		done:  make(chan struct{}),
+		board: chess.InitialBoard(),
+		opts:  make(map[string]string),
+	}
+	go e.loop()
+	return e
+}
+
+func NewProcess(o EngineOptions) uci.Process {
+	return NewEngine(o)
+}
+
+func (e *Engine) loop() {
+	for {
+		select {
+		case line := <-e.in:
+			e.mu.Lock()
+			e.lines = append(e.lines, line)
+			e.mu.Unlock()
+			if e.o.Hook != nil && e.o.Hook(e, line) {
+				continue
+			}
+			e.handle(line)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// Reply sends the line to the client. It is safe to call it from any goroutine.
+func (e *Engine) Reply(line string) {
+	select {
+	case e.out <- line:
+	case <-e.done:
+	}
+}
+
+// Process handles the line as if it was received by the engine, skipping the hook.
+func (e *Engine) Process(line string) {
+	e.handle(line)
+}
+
+// Received returns all the lines received from the client so far.
+func (e *Engine) Received() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.lines...)
+}
+
+func (e *Engine) Board() *chess.Board {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.board.Clone()
+}
+
+func (e *Engine) Option(name string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.opts[strings.ToLower(name)]
+	return v, ok
+}
+
+func (e *Engine) Searching() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pending != nil
+}
+
+func (e *Engine) handle(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case "uci":
+		e.Reply("id name " + e.o.Name)
+		e.Reply("id author " + e.o.Author)
+		for _, opt := range e.o.Options {
+			e.Reply(opt)
+		}
+		e.Reply("uciok")
+	case "isready":
+		e.Reply("readyok")
+	case "setoption":
+		e.onSetOption(line)
+	case "ucinewgame":
+		e.mu.Lock()
+		e.board = chess.InitialBoard()
+		e.mu.Unlock()
+	case "position":
+		if err := e.onPosition(fields[1:]); err != nil {
+			e.Reply("info string bad position: " + err.Error())
+		}
+	case "go":
+		e.onGo(fields[1:])
+	case "stop":
+		e.finishSearch()
+	case "ponderhit":
+		e.mu.Lock()
+		p := e.pending
+		if p != nil {
+			p.ponder = false
+		}
+		e.mu.Unlock()
+		if p != nil && !p.infinite {
+			e.finishSearch()
+		}
+	case "quit":
+		e.Kill()
+	}
+}
+
+func (e *Engine) onSetOption(line string) {
+	rest, ok := strings.CutPrefix(line, "setoption name ")
+	if !ok {
+		return
+	}
+	name, value, _ := strings.Cut(rest, " value")
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.opts[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+}
+
+func (e *Engine) onPosition(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no position")
+	}
+	var b *chess.Board
+	switch args[0] {
+	case "startpos":
+		b = chess.InitialBoard()
+		args = args[1:]
+	case "fen":
+		end := len(args)
+		for i, a := range args {
+			if a == "moves" {
+				end = i
+				break
+			}
+		}
+		var err error
+		b, err = chess.BoardFromFEN(strings.Join(args[1:end], " "))
+		if err != nil {
+			return fmt.Errorf("parse fen: %w", err)
+		}
+		args = args[end:]
+	default:
+		return fmt.Errorf("bad position kind %q", args[0])
+	}
+	if len(args) != 0 && args[0] == "moves" {
+		for _, m := range args[1:] {
+			if _, err := b.MakeMoveUCI(m); err != nil {
+				return fmt.Errorf("make move %q: %w", m, err)
+			}
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.board = b
+	return nil
+}
+
+func (e *Engine) onGo(args []string) {
+	p := &pendingSearch{}
+	depth := int64(1)
+	nodes := int64(-1)
+	var searchMoves []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "ponder":
+			p.ponder = true
+		case "infinite":
+			p.infinite = true
+		case "depth", "nodes":
+			if i+1 < len(args) {
+				v, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err == nil {
+					if args[i] == "depth" {
+						depth = v
+					} else {
+						nodes = v
+					}
+				}
+				i++
+			}
+		case "searchmoves":
+			for i+1 < len(args) {
+				if _, err := chess.UCIMoveFromString(args[i+1]); err != nil {
+					break
+				}
+				searchMoves = append(searchMoves, args[i+1])
+				i++
+			}
+		}
+	}
+
+	b := e.Board()
+	candidates := b.GenLegalMoves(chess.MoveGenAll, nil)
+	if len(searchMoves) != 0 {
+		filtered := candidates[:0]
+		for _, m := range candidates {
+			for _, s := range searchMoves {
+				if m.UCI() == s {
+					filtered = append(filtered, m)
+					break
+				}
+			}
+		}
+		candidates = filtered
+	}
+	if len(candidates) == 0 {
+		e.Reply("bestmove 0000")
+		return
+	}
+	var lines []Line
+	if e.o.Lines != nil {
+		lines = e.o.Lines(b, candidates)
+		multiPV := 1
+		if v, ok := e.Option("MultiPV"); ok {
+			if n, err := strconv.Atoi(v); err == nil && n > 1 {
+				multiPV = n
+			}
+		}
+		lines = lines[:min(len(lines), multiPV)]
+	}
+	if len(lines) == 0 {
+		lines = []Line{{Move: e.o.Choose(b, candidates), Score: e.o.Eval(b)}}
+	}
+	p.best = lines[0].Move
+	u := b.MakeLegalMove(p.best)
+	if replies := b.GenLegalMoves(chess.MoveGenAll, nil); len(replies) != 0 {
+		p.ponderMv = replies[0]
+		p.hasReply = true
+	}
+	b.UnmakeMove(u)
+
+	if nodes < 0 {
+		nodes = int64(len(candidates))
+	}
+	for i, l := range lines {
+		multiPV := ""
+		if len(lines) > 1 {
+			multiPV = fmt.Sprintf(" multipv %v", i+1)
+		}
+		p.info = append(p.info, fmt.Sprintf("info depth %v nodes %v time 0%v score %v pv %v",
+			depth, nodes, multiPV, scoreString(l.Score), l.Move.UCI()))
+	}
+
+	e.mu.Lock()
+	e.pending = p
+	e.mu.Unlock()
+
+	for _, info := range p.info {
+		e.Reply(info)
+	}
+	if !p.ponder && !p.infinite {
+		e.finishSearch()
+	}
+}
+
+func (e *Engine) finishSearch() {
+	e.mu.Lock()
+	p := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	if p == nil {
+		return
+	}
+	if p.hasReply {
+		e.Reply(fmt.Sprintf("bestmove %v ponder %v", p.best.UCI(), p.ponderMv.UCI()))
+	} else {
+		e.Reply(fmt.Sprintf("bestmove %v", p.best.UCI()))
+	}
+}
+
+func (e *Engine) Send(s string) error {
+	select {
+	case e.in <- s:
+		return nil
+	case <-e.done:
+		return fmt.Errorf("engine terminated")
+	}
+}
+
+func (e *Engine) Recv() (string, error) {
+	select {
+	case s := <-e.out:
+		return s, nil
+	case <-e.done:
+		select {
+		case s := <-e.out:
+			return s, nil
+		default:
+			return "", io.EOF
+		}
+	}
+}
+
+func (e *Engine) Done() <-chan struct{} { return e.done }
+func (e *Engine) Err() error            { return nil }
+
+func (e *Engine) Kill() {
+	e.once.Do(func() { close(e.done) })
+}
+
+// Sleep is a helper for hooks that simulate slow engines. It returns false if the engine was
+// terminated while sleeping.
+func (e *Engine) Sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-e.done:
+		return false
+	}
+}
+
+func scoreString(s uci.Score) string {
+	if m, ok := s.Mate(); ok {
+		return fmt.Sprintf("mate %v", m)
+	}
+	cp, _ := s.Centipawns()
+	return fmt.Sprintf("cp %v", cp)
+}
+
+var pieceValues = [...]int32{
+	chess.PiecePawn:   100,
+	chess.PieceKnight: 300,
+	chess.PieceBishop: 300,
+	chess.PieceRook:   500,
+	chess.PieceQueen:  900,
+	chess.PieceKing:   0,
+}
+
+// Material returns the material balance from the side to move's point of view.
+func Material(b *chess.Board) uci.Score {
+	var res int32
+	for c := range chess.CoordMax {
+		cell := b.Get(c)
+		col, ok := cell.Color()
+		if !ok {
+			continue
+		}
+		p, _ := cell.Piece()
+		if col == b.Side() {
+			res += pieceValues[p]
+		} else {
+			res -= pieceValues[p]
+		}
+	}
+	return uci.ScoreCentipawns(res)
+}